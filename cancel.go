@@ -0,0 +1,141 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// CancelCommand is the reserved command cancelling the running commands
+// and jobs of the tenant and plugin with the request ID given as
+// "request_id" argument. Their handlers' contexts are cancelled, the calls
+// fail with CANCELLED and the jobs end cancelled.
+const CancelCommand = "__cancel"
+
+// CancelResult is the result of the cancel command.
+type CancelResult struct {
+	RequestID string `json:"request_id"`
+
+	// Cancelled is the number of running commands and jobs cancelled, zero
+	// if none was running under the request ID.
+	Cancelled int `json:"cancelled"`
+}
+
+// errCommandCancelled is the cause of the context of a command cancelled by the host.
+var errCommandCancelled = errors.New("command cancelled by the host")
+
+// cancelKey identifies the commands of a request to a tenant's plugin.
+type cancelKey struct {
+	key       instanceKey
+	requestID string
+}
+
+// cancelRegistry holds the cancel functions of running commands by request ID.
+type cancelRegistry struct {
+	mu     sync.Mutex
+	nextID uint64
+	calls  map[cancelKey]map[uint64]context.CancelCauseFunc
+}
+
+// newCancelRegistry creates an empty cancel registry.
+func newCancelRegistry() *cancelRegistry {
+	return &cancelRegistry{
+		calls: make(map[cancelKey]map[uint64]context.CancelCauseFunc),
+	}
+}
+
+// register makes the command or job running under ctx cancellable by its
+// request ID, the one its handler sees in ctx.Request. The returned
+// function unregisters it and must be called when the command ends.
+func (r *cancelRegistry) register(ctx context.Context, instance instanceKey) (context.Context, func()) {
+	ctx, request := withRequestInfo(ctx)
+	ctx, cancel := context.WithCancelCause(ctx)
+	key := cancelKey{key: instance, requestID: request.RequestID}
+
+	r.mu.Lock()
+	id := r.nextID
+	r.nextID++
+	if r.calls[key] == nil {
+		r.calls[key] = make(map[uint64]context.CancelCauseFunc)
+	}
+	r.calls[key][id] = cancel
+	r.mu.Unlock()
+
+	return ctx, func() {
+		r.mu.Lock()
+		delete(r.calls[key], id)
+		if len(r.calls[key]) == 0 {
+			delete(r.calls, key)
+		}
+		r.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+// cancel cancels the running commands of a request to a tenant's plugin
+// and returns how many there were.
+func (r *cancelRegistry) cancel(instance instanceKey, requestID string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	calls := r.calls[cancelKey{key: instance, requestID: requestID}]
+	for _, cancel := range calls {
+		cancel(errCommandCancelled)
+	}
+	return len(calls)
+}
+
+// cancelCommand answers the reserved cancel command.
+func (s *Server) cancelCommand(key instanceKey, req *pluginpb.ExecuteCommandRequest) *pluginpb.ExecuteCommandResponse {
+	var requestID string
+	if len(req.Args) > 0 {
+		var arg interface{}
+		if err := json.Unmarshal(req.Args[0], &arg); err != nil {
+			return reservedResponse(nil, InvalidArgument("failed to unmarshal argument: %v", err))
+		}
+		switch value := arg.(type) {
+		case string:
+			requestID = value
+		case map[string]interface{}:
+			requestID, _ = value["request_id"].(string)
+		}
+	}
+	if requestID == "" {
+		return reservedResponse(nil, InvalidArgument("%s requires a request ID", CancelCommand))
+	}
+
+	return reservedResponse(CancelResult{
+		RequestID: requestID,
+		Cancelled: s.cancels.cancel(key, requestID),
+	}, nil)
+}
+
+// cancelError reports a failure as CANCELLED if the host cancelled the
+// command, or it outlived the drain timeout of a shutdown or disable,
+// whatever error the handler returned. Panics keep their code.
+func cancelError(ctx context.Context, err error) error {
+	if err == nil || isPanic(err) {
+		return err
+	}
+	switch cause := context.Cause(ctx); {
+	case errors.Is(cause, errCommandCancelled):
+		return Cancelled("command cancelled").WithCause(err)
+	case errors.Is(cause, errDrainTimeout):
+		return Cancelled("%v", cause).WithCause(err)
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc/metadata"
+)
+
+// cancelPlugin has a "wait" command sending its request ID to started and
+// running until cancelled, and the async "forever" command.
+func cancelPlugin(t *testing.T) (*BasePlugin, <-chan string) {
+	t.Helper()
+	plugin := jobsPlugin(t)
+	started := make(chan string, 1)
+	must(t, plugin.RegisterCommand("wait", func(ctx *Context) (interface{}, error) {
+		started <- ctx.Request.RequestID
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	return plugin, started
+}
+
+// cancel calls __cancel for the target and returns how many commands it cancelled.
+func (tt testTarget) cancel(t *testing.T, server *Server, requestID string) int {
+	t.Helper()
+	var result CancelResult
+	tt.run(t, server, &result, CancelCommand, map[string]string{"request_id": requestID})
+	if result.RequestID != requestID {
+		t.Fatalf("__cancel result %+v, want request ID %s", result, requestID)
+	}
+	return result.Cancelled
+}
+
+// withRequestID returns a context of a call sent with requestID.
+func withRequestID(t *testing.T, requestID string) context.Context {
+	return metadata.NewIncomingContext(testContext(t), metadata.Pairs(MetadataRequestID, requestID))
+}
+
+func TestCancelRunningCommand(t *testing.T) {
+	plugin, started := cancelPlugin(t)
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() { result <- target.execute(t, server, withRequestID(t, "req-7"), nil, "wait") }()
+	<-started
+
+	// Only the commands of the tenant's plugin are cancelled
+	otherTenant := testTarget{tenantID: newTestTarget().tenantID, pluginID: target.pluginID}
+	otherPlugin := testTarget{tenantID: target.tenantID, pluginID: newTestTarget().pluginID}
+	for _, other := range []testTarget{otherTenant, otherPlugin} {
+		if cancelled := other.cancel(t, server, "req-7"); cancelled != 0 {
+			t.Fatalf("__cancel of another target cancelled %d commands, want 0", cancelled)
+		}
+	}
+
+	if cancelled := target.cancel(t, server, "req-7"); cancelled != 1 {
+		t.Fatalf("__cancel cancelled %d commands, want 1", cancelled)
+	}
+	if perr := <-result; perr.GetCode() != string(CodeCancelled) {
+		t.Fatalf("cancelled command: error = %v, want %s", perr, CodeCancelled)
+	}
+
+	server.cancels.mu.Lock()
+	defer server.cancels.mu.Unlock()
+	if len(server.cancels.calls) != 0 {
+		t.Fatalf("%d requests still registered after the command ended", len(server.cancels.calls))
+	}
+}
+
+func TestCancelByGeneratedRequestID(t *testing.T) {
+	plugin, started := cancelPlugin(t)
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() { result <- target.execute(t, server, context.Background(), nil, "wait") }()
+
+	if cancelled := target.cancel(t, server, <-started); cancelled != 1 {
+		t.Fatalf("__cancel by the ctx.Request ID cancelled %d commands, want 1", cancelled)
+	}
+	if perr := <-result; perr.GetCode() != string(CodeCancelled) {
+		t.Fatalf("cancelled command: error = %v, want %s", perr, CodeCancelled)
+	}
+}
+
+func TestCancelAsyncJob(t *testing.T) {
+	plugin, _ := cancelPlugin(t)
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	var handle JobHandle
+	if perr := target.execute(t, server, withRequestID(t, "req-job"), &handle, "forever"); perr != nil {
+		t.Fatalf("forever failed: %v", perr)
+	}
+	if cancelled := target.cancel(t, server, "req-job"); cancelled != 1 {
+		t.Fatalf("__cancel cancelled %d jobs, want 1", cancelled)
+	}
+	if job := target.awaitJob(t, server, handle.JobID); job.Status != JobCancelled {
+		t.Fatalf("job = %+v, want it cancelled", job)
+	}
+}
+
+func TestCancelArguments(t *testing.T) {
+	server := newTestServer(t, NewBasePlugin())
+	target := newTestTarget()
+
+	if cancelled := target.cancel(t, server, "not-running"); cancelled != 0 {
+		t.Fatalf("__cancel of no running command cancelled %d, want 0", cancelled)
+	}
+	var result CancelResult
+	target.run(t, server, &result, CancelCommand, "positional")
+	if result.RequestID != "positional" {
+		t.Fatalf("__cancel with a positional request ID = %+v", result)
+	}
+	if perr := target.execute(t, server, testContext(t), nil, CancelCommand); perr.GetCode() != string(CodeInvalidArgument) {
+		t.Fatalf("__cancel without a request ID: error = %v, want %s", perr, CodeInvalidArgument)
+	}
+}
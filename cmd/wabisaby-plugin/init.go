@@ -0,0 +1,264 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+)
+
+// bases are the plugin kinds init scaffolds, by -base value, with the SDK
+// type the plugin embeds.
+var bases = map[string]string{
+	"basic":      "BasePlugin",
+	"downloader": "ContentDownloaderPlugin",
+	"resolver":   "MetadataResolverPlugin",
+	"storage":    "StorageProviderPlugin",
+}
+
+// scaffold is the data of the project templates.
+type scaffold struct {
+	Module     string
+	ID         string
+	Name       string
+	Base       string
+	Embed      string
+	SDKVersion string
+}
+
+// runInit scaffolds a plugin project in a new or empty directory.
+func runInit(args []string) error {
+	flags := newFlagSet("init", "<dir>")
+	base := flags.String("base", "basic", "plugin kind: "+strings.Join(baseNames(), ", "))
+	module := flags.String("module", "", "module path, defaults to the directory name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return fmt.Errorf("directory required")
+	}
+
+	embed, ok := bases[*base]
+	if !ok {
+		return fmt.Errorf("unknown base %q, want one of %s", *base, strings.Join(baseNames(), ", "))
+	}
+
+	dir := flags.Arg(0)
+	id := pluginID(filepath.Base(dir))
+	data := scaffold{
+		Module:     *module,
+		ID:         id,
+		Name:       pluginName(id),
+		Base:       *base,
+		Embed:      embed,
+		SDKVersion: sdk.SDKVersion,
+	}
+	if data.Module == "" {
+		data.Module = id
+	}
+
+	files := map[string]*template.Template{
+		"go.mod":     goModTemplate,
+		"main.go":    mainTemplate,
+		".gitignore": gitignoreTemplate,
+	}
+	for name := range files {
+		if _, err := os.Stat(filepath.Join(dir, name)); !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%s already exists", filepath.Join(dir, name))
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	for name, tmpl := range files {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return fmt.Errorf("failed to render %s: %w", name, err)
+		}
+		content := buf.Bytes()
+		if strings.HasSuffix(name, ".go") {
+			var err error
+			if content, err = format.Source(content); err != nil {
+				return fmt.Errorf("failed to format %s: %w", name, err)
+			}
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	fmt.Printf("Created %s plugin %q in %s\n\nNext steps:\n  cd %s\n  go mod tidy\n  go run . --dev\n", *base, id, dir, dir)
+	return nil
+}
+
+// baseNames returns the -base values in ascending order.
+func baseNames() []string {
+	names := make([]string, 0, len(bases))
+	for name := range bases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// nonIDChars matches the characters replaced in plugin IDs.
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// pluginID derives a plugin ID such as "my-plugin" from a directory name.
+func pluginID(dir string) string {
+	id := strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(dir), "-"), "-")
+	if id == "" {
+		return "plugin"
+	}
+	return id
+}
+
+// pluginName derives a human-readable name such as "My Plugin" from an ID.
+func pluginName(id string) string {
+	words := strings.Split(id, "-")
+	for i, word := range words {
+		words[i] = strings.ToUpper(word[:1]) + word[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+var goModTemplate = template.Must(template.New("go.mod").Parse(`module {{.Module}}
+
+go 1.24
+
+require github.com/wabisaby/wabisaby-plugin-sdk v{{.SDKVersion}}
+`))
+
+var gitignoreTemplate = template.Must(template.New(".gitignore").Parse(`# Dev mode storage
+.wabisaby-dev/
+`))
+
+var mainTemplate = template.Must(template.New("main.go").Parse(`package main
+
+import (
+	"log"
+
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+)
+
+// Plugin is the {{.ID}} plugin.
+type Plugin struct {
+	*sdk.{{.Embed}}
+}
+
+// Manifest implements sdk.ManifestProvider.
+func (p *Plugin) Manifest() sdk.Manifest {
+	return sdk.Manifest{
+		ID:      "{{.ID}}",
+		Name:    "{{.Name}}",
+		Version: "0.1.0",
+{{- if or (eq .Base "downloader") (eq .Base "resolver")}}
+		Capabilities: []sdk.Capability{sdk.CapabilityHTTP},
+		HTTPDomains:  []string{"example.com"},
+{{- end}}
+	}
+}
+{{if eq .Base "basic"}}
+// HelloArgs are the arguments of the hello command.
+type HelloArgs struct {
+	Name string ` + "`" + `json:"name"` + "`" + `
+}
+
+// hello greets someone.
+func (p *Plugin) hello(ctx *sdk.Context, args *HelloArgs) (interface{}, error) {
+	ctx.Logger.Info("Greeting", "name", args.Name)
+	return map[string]string{"message": "Hello, " + args.Name + "!"}, nil
+}
+{{else if eq .Base "downloader"}}
+// Download implements sdk.ContentDownloader.
+func (p *Plugin) Download(ctx *sdk.Context, req *sdk.DownloadRequest) (*sdk.DownloadResult, error) {
+	return nil, sdk.NewError(sdk.CodeNotSupported, "download of %s is not implemented", req.URL)
+}
+
+// CanHandle implements sdk.ContentDownloader.
+func (p *Plugin) CanHandle(url string) bool {
+	return false
+}
+
+// SupportedDomains implements sdk.ContentDownloader.
+func (p *Plugin) SupportedDomains() []string {
+	return []string{"example.com"}
+}
+{{else if eq .Base "resolver"}}
+// ResolveURL implements sdk.MetadataResolver.
+func (p *Plugin) ResolveURL(ctx *sdk.Context, req *sdk.ResolveURLRequest) (*sdk.ResolveResult, error) {
+	return nil, sdk.NewError(sdk.CodeNotSupported, "resolving %s is not implemented", req.URL)
+}
+
+// Search implements sdk.MetadataResolver.
+func (p *Plugin) Search(ctx *sdk.Context, req *sdk.SearchRequest) ([]*sdk.SearchResult, error) {
+	return []*sdk.SearchResult{}, nil
+}
+
+// CanHandle implements sdk.MetadataResolver.
+func (p *Plugin) CanHandle(url string) bool {
+	return false
+}
+
+// SupportedDomains implements sdk.MetadataResolver.
+func (p *Plugin) SupportedDomains() []string {
+	return []string{"example.com"}
+}
+{{else if eq .Base "storage"}}
+// UploadAudio implements sdk.StorageProvider.
+func (p *Plugin) UploadAudio(ctx *sdk.Context, req *sdk.UploadAudioRequest) (string, error) {
+	return "", sdk.NewError(sdk.CodeNotSupported, "upload of %s is not implemented", req.FilePath)
+}
+
+// GetFileSizeMB implements sdk.StorageProvider.
+func (p *Plugin) GetFileSizeMB(ctx *sdk.Context, cdnURL string) (float64, error) {
+	return 0, sdk.NotFound("file not found: %s", cdnURL)
+}
+
+// DeleteAudio implements sdk.StorageProvider.
+func (p *Plugin) DeleteAudio(ctx *sdk.Context, cdnURL string) error {
+	return sdk.NotFound("file not found: %s", cdnURL)
+}
+{{end}}
+func main() {
+	plugin := &Plugin{
+		{{.Embed}}: sdk.New{{if eq .Base "basic"}}BasePlugin{{else}}{{.Embed}}{{end}}(),
+	}
+{{- if eq .Base "basic"}}
+	err := plugin.RegisterCommand("hello", plugin.hello,
+		sdk.WithDescription("Greets someone"),
+		sdk.WithParameters(sdk.Param("name", sdk.ParamTypeString, "Who to greet")),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+{{- end}}
+
+	// Run with --dev to try the plugin without the host
+	if err := sdk.Serve(plugin); err != nil {
+		log.Fatal(err)
+	}
+}
+`))
@@ -0,0 +1,102 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+)
+
+func TestInitScaffoldsEveryBase(t *testing.T) {
+	for base, embed := range bases {
+		t.Run(base, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "My Plugin")
+			if err := runInit([]string{"-base", base, "-module", "example.com/my-plugin", dir}); err != nil {
+				t.Fatalf("init: %v", err)
+			}
+
+			goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(string(goMod), "module example.com/my-plugin\n") || !strings.Contains(string(goMod), "v"+sdk.SDKVersion) {
+				t.Errorf("go.mod = %q, want the module and SDK version", goMod)
+			}
+
+			mainGo := filepath.Join(dir, "main.go")
+			file, err := parser.ParseFile(token.NewFileSet(), mainGo, nil, parser.ParseComments)
+			if err != nil {
+				t.Fatalf("main.go does not parse: %v", err)
+			}
+			if file.Name.Name != "main" {
+				t.Errorf("main.go package = %s, want main", file.Name.Name)
+			}
+			source, _ := os.ReadFile(mainGo)
+			for _, want := range []string{"*sdk." + embed, `"my-plugin"`, `"My Plugin"`} {
+				if !strings.Contains(string(source), want) {
+					t.Errorf("main.go does not contain %s", want)
+				}
+			}
+
+			if _, err := os.Stat(filepath.Join(dir, ".gitignore")); err != nil {
+				t.Errorf(".gitignore not written: %v", err)
+			}
+		})
+	}
+}
+
+func TestInitRefusesExistingProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := runInit([]string{dir}); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("init in an existing project: error = %v, want it refused", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); !os.IsNotExist(err) {
+		t.Fatalf("go.mod written in an existing project: %v", err)
+	}
+}
+
+func TestInitArguments(t *testing.T) {
+	if err := runInit([]string{"-base", "teleporter", t.TempDir()}); err == nil || !strings.Contains(err.Error(), "unknown base") {
+		t.Errorf("unknown base: error = %v", err)
+	}
+	if err := runInit(nil); err == nil {
+		t.Error("init without a directory succeeded")
+	}
+}
+
+func TestPluginIDAndName(t *testing.T) {
+	tests := []struct {
+		dir, id, name string
+	}{
+		{"my-plugin", "my-plugin", "My Plugin"},
+		{"My_Cool Plugin!", "my-cool-plugin", "My Cool Plugin"},
+		{"--", "plugin", "Plugin"},
+	}
+	for _, tt := range tests {
+		id := pluginID(tt.dir)
+		if id != tt.id {
+			t.Errorf("pluginID(%q) = %q, want %q", tt.dir, id, tt.id)
+		}
+		if name := pluginName(id); name != tt.name {
+			t.Errorf("pluginName(%q) = %q, want %q", id, name, tt.name)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+)
+
+// runInvoke builds the plugin, runs a single command against a fake
+// capabilities backend and prints its result.
+func runInvoke(args []string) error {
+	flags := newFlagSet("invoke", "<command> [json args...]")
+	dir := flags.String("dir", ".", "directory of the plugin's main package")
+	configFile := flags.String("config", "", "JSON file with the plugin config")
+	fixturesFile := flags.String("fixtures", "", "JSON file with songs, users and secrets")
+	storageDir := flags.String("storage", devcaps.DefaultStorageDir, "directory storage is kept in")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of the command")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 {
+		flags.Usage()
+		return fmt.Errorf("command name required")
+	}
+
+	command := flags.Arg(0)
+	commandArgs := make([][]byte, 0, flags.NArg()-1)
+	for _, arg := range flags.Args()[1:] {
+		if !json.Valid([]byte(arg)) {
+			return fmt.Errorf("argument %s is not valid JSON", arg)
+		}
+		commandArgs = append(commandArgs, []byte(arg))
+	}
+
+	config, err := readConfig(*configFile)
+	if err != nil {
+		return err
+	}
+	fixtures := devcaps.DefaultFixtures()
+	if *fixturesFile != "" {
+		if fixtures, err = devcaps.LoadFixtures(*fixturesFile); err != nil {
+			return err
+		}
+	}
+
+	binary, cleanup, err := buildPlugin(*dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	backend := devcaps.New(
+		devcaps.WithStorageDir(*storageDir),
+		devcaps.WithFixtures(fixtures),
+	)
+	plugin, err := launchPlugin(binary, backend)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if err := plugin.initialize(ctx, config); err != nil {
+		plugin.close()
+		return err
+	}
+
+	start := time.Now()
+	data, execErr := plugin.execute(ctx, command, commandArgs, timeout.Milliseconds())
+	elapsed := time.Since(start)
+	if err := plugin.shutdown(ctx); err != nil && execErr == nil {
+		execErr = err
+	}
+	if execErr != nil {
+		return execErr
+	}
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		out.Write(data)
+	}
+	fmt.Println(out.String())
+	fmt.Fprintf(os.Stderr, "%s took %s\n", command, elapsed.Round(time.Millisecond))
+	return nil
+}
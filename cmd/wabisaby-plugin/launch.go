@@ -0,0 +1,177 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/hashicorp/go-hclog"
+	hashicorp_plugin "github.com/hashicorp/go-plugin"
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// buildPlugin builds the plugin package in dir into a temporary binary.
+// The returned function removes the binary.
+func buildPlugin(dir string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "wabisaby-plugin-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(tmpDir) }
+
+	binary := filepath.Join(tmpDir, "plugin")
+	cmd := exec.Command("go", "build", "-o", binary, ".")
+	cmd.Dir = dir
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to build plugin: %w", err)
+	}
+	return binary, cleanup, nil
+}
+
+// launchedPlugin is a plugin binary served through go-plugin, connected
+// to a fake capabilities backend.
+type launchedPlugin struct {
+	client      *hashicorp_plugin.Client
+	exec        pluginpb.PluginExecutionServiceClient
+	stopBackend func()
+	tenantID    string
+	pluginID    string
+}
+
+// launchPlugin starts binary the way the host does, with the handshake of
+// HandshakeConfig(), and dispenses its execution service.
+func launchPlugin(binary string, backend *devcaps.Backend) (*launchedPlugin, error) {
+	capabilitiesAddr, stopBackend, err := backend.Start("127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start capabilities backend: %w", err)
+	}
+
+	cmd := exec.Command(binary)
+	cmd.Env = append(os.Environ(), "WABISABY_CAPABILITIES_ADDR="+capabilitiesAddr)
+	client := hashicorp_plugin.NewClient(&hashicorp_plugin.ClientConfig{
+		HandshakeConfig: sdk.HandshakeConfig(),
+		VersionedPlugins: map[int]hashicorp_plugin.PluginSet{
+			sdk.ProtocolVersion: {"plugin": &sdk.PluginGRPC{}},
+		},
+		Cmd:              cmd,
+		AllowedProtocols: []hashicorp_plugin.Protocol{hashicorp_plugin.ProtocolGRPC},
+		Logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin",
+			Level:  hclog.Warn,
+			Output: os.Stderr,
+		}),
+	})
+
+	launched := &launchedPlugin{
+		client:      client,
+		stopBackend: stopBackend,
+		tenantID:    uuid.NewString(),
+		pluginID:    uuid.NewString(),
+	}
+
+	rpcClient, err := client.Client()
+	if err != nil {
+		launched.close()
+		return nil, fmt.Errorf("failed to launch plugin: %w", err)
+	}
+	raw, err := rpcClient.Dispense("plugin")
+	if err != nil {
+		launched.close()
+		return nil, fmt.Errorf("failed to dispense plugin: %w", err)
+	}
+	execClient, ok := raw.(pluginpb.PluginExecutionServiceClient)
+	if !ok {
+		launched.close()
+		return nil, fmt.Errorf("plugin dispensed unexpected type %T", raw)
+	}
+	launched.exec = execClient
+	return launched, nil
+}
+
+// initialize initializes the plugin and enables it for the launch tenant.
+func (p *launchedPlugin) initialize(ctx context.Context, config []byte) error {
+	initResp, err := p.exec.InitializePlugin(ctx, &pluginpb.InitializePluginRequest{
+		TenantId: p.tenantID,
+		PluginId: p.pluginID,
+		Config:   config,
+	})
+	if err != nil {
+		return fmt.Errorf("InitializePlugin failed: %w", err)
+	}
+	if initResp.Error != nil {
+		return fmt.Errorf("InitializePlugin failed: %s: %s", initResp.Error.Code, initResp.Error.Message)
+	}
+
+	enableResp, err := p.exec.EnablePlugin(ctx, &pluginpb.EnablePluginRequest{
+		TenantId: p.tenantID,
+		PluginId: p.pluginID,
+		Config:   config,
+	})
+	if err != nil {
+		return fmt.Errorf("EnablePlugin failed: %w", err)
+	}
+	if enableResp.Error != nil {
+		return fmt.Errorf("EnablePlugin failed: %s: %s", enableResp.Error.Code, enableResp.Error.Message)
+	}
+	return nil
+}
+
+// execute runs a command for the launch tenant and returns its JSON result.
+func (p *launchedPlugin) execute(ctx context.Context, command string, args [][]byte, timeoutMs int64) ([]byte, error) {
+	resp, err := p.exec.ExecuteCommand(ctx, &pluginpb.ExecuteCommandRequest{
+		TenantId:  p.tenantID,
+		PluginId:  p.pluginID,
+		Command:   command,
+		Args:      args,
+		TimeoutMs: timeoutMs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ExecuteCommand failed: %w", err)
+	}
+	if pluginErr := resp.GetError(); pluginErr != nil {
+		return nil, fmt.Errorf("%s failed: %s: %s", command, pluginErr.Code, pluginErr.Message)
+	}
+	return resp.GetData(), nil
+}
+
+// shutdown shuts the plugin down, then stops it and the backend.
+func (p *launchedPlugin) shutdown(ctx context.Context) error {
+	defer p.close()
+
+	resp, err := p.exec.ShutdownPlugin(ctx, &pluginpb.ShutdownPluginRequest{
+		TenantId: p.tenantID,
+		PluginId: p.pluginID,
+	})
+	if err != nil {
+		return fmt.Errorf("ShutdownPlugin failed: %w", err)
+	}
+	if resp.Error != nil {
+		return fmt.Errorf("ShutdownPlugin failed: %s: %s", resp.Error.Code, resp.Error.Message)
+	}
+	return nil
+}
+
+// close stops the plugin process and the backend.
+func (p *launchedPlugin) close() {
+	p.client.Kill()
+	p.stopBackend()
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+// Command wabisaby-plugin scaffolds, validates and runs WabiSaby plugins
+// without the host.
+//
+// Usage:
+//
+//	wabisaby-plugin init [-base kind] [-module path] <dir>
+//	wabisaby-plugin validate [-dir dir]
+//	wabisaby-plugin invoke [-dir dir] [-config file] [-timeout d] <command> [json args...]
+//	wabisaby-plugin manifest [-dir dir]
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// usage describes the subcommands.
+const usage = `Usage: wabisaby-plugin <command> [flags] [args]
+
+Commands:
+  init      scaffold a plugin project
+  validate  build a plugin, launch it and check its command metadata
+  invoke    run a single command against a fake capabilities backend
+  manifest  print the plugin manifest
+
+Run "wabisaby-plugin <command> -h" for the flags of a command.
+`
+
+// commands are the subcommands, by name.
+var commands = map[string]func(args []string) error{
+	"init":     runInit,
+	"validate": runValidate,
+	"invoke":   runInvoke,
+	"manifest": runManifest,
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	run, ok := commands[os.Args[1]]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+
+	if err := run(os.Args[2:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "wabisaby-plugin %s: %v\n", os.Args[1], err)
+		os.Exit(1)
+	}
+}
+
+// newFlagSet creates the flag set of a subcommand.
+func newFlagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: wabisaby-plugin %s\n\nFlags:\n", strings.TrimSpace(name+" [flags] "+args))
+		flags.PrintDefaults()
+	}
+	return flags
+}
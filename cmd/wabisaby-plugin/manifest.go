@@ -0,0 +1,59 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// runManifest builds the plugin and prints its manifest, as written by
+// running the plugin with --manifest.
+func runManifest(args []string) error {
+	flags := newFlagSet("manifest", "")
+	dir := flags.String("dir", ".", "directory of the plugin's main package")
+	output := flags.String("o", "", "file to write the manifest to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	binary, cleanup, err := buildPlugin(*dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	path := filepath.Join(filepath.Dir(binary), "manifest.json")
+	cmd := exec.Command(binary, "--manifest", path)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to generate manifest: %w", err)
+	}
+
+	manifest, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("plugin wrote no manifest: it must implement sdk.ManifestProvider and exit with the error of sdk.Serve")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if *output != "" {
+		return os.WriteFile(*output, manifest, 0o644)
+	}
+	_, err = os.Stdout.Write(manifest)
+	return err
+}
@@ -0,0 +1,130 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+)
+
+// runValidate builds the plugin, launches it through go-plugin and checks
+// the metadata of its commands.
+func runValidate(args []string) error {
+	flags := newFlagSet("validate", "")
+	dir := flags.String("dir", ".", "directory of the plugin's main package")
+	configFile := flags.String("config", "", "JSON file with the plugin config")
+	timeout := flags.Duration("timeout", 30*time.Second, "timeout of the whole validation")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	config, err := readConfig(*configFile)
+	if err != nil {
+		return err
+	}
+
+	binary, cleanup, err := buildPlugin(*dir)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	// Validation starts from empty storage
+	storageDir, err := os.MkdirTemp("", "wabisaby-plugin-storage-")
+	if err != nil {
+		return fmt.Errorf("failed to create storage directory: %w", err)
+	}
+	defer os.RemoveAll(storageDir)
+
+	plugin, err := launchPlugin(binary, devcaps.New(devcaps.WithStorageDir(storageDir)))
+	if err != nil {
+		return err
+	}
+	if err := plugin.initialize(ctx, config); err != nil {
+		plugin.close()
+		return err
+	}
+
+	data, err := plugin.execute(ctx, sdk.CommandsCommand, nil, 0)
+	if err != nil {
+		plugin.close()
+		return err
+	}
+	var commands []sdk.CommandMetadata
+	if err := json.Unmarshal(data, &commands); err != nil {
+		plugin.close()
+		return fmt.Errorf("invalid %s result: %w", sdk.CommandsCommand, err)
+	}
+
+	if err := plugin.shutdown(ctx); err != nil {
+		return err
+	}
+
+	if err := validateCommands(commands); err != nil {
+		return fmt.Errorf("invalid command metadata:\n%w", err)
+	}
+
+	fmt.Printf("ok: %d commands\n", len(commands))
+	for _, cmd := range commands {
+		params := make([]string, 0, len(cmd.Parameters))
+		for _, param := range cmd.Parameters {
+			params = append(params, fmt.Sprintf("%s %s", param.Name, param.Type))
+		}
+		fmt.Printf("  %s(%s)\n", cmd.Name, strings.Join(params, ", "))
+	}
+	return nil
+}
+
+// validateCommands checks the metadata of every command, and that no
+// command is listed twice.
+func validateCommands(commands []sdk.CommandMetadata) error {
+	var errs []error
+	seen := make(map[string]bool, len(commands))
+	for _, cmd := range commands {
+		if seen[cmd.Name] {
+			errs = append(errs, fmt.Errorf("  duplicate command %q", cmd.Name))
+		}
+		seen[cmd.Name] = true
+		if err := cmd.Validate(); err != nil {
+			for _, line := range strings.Split(err.Error(), "\n") {
+				errs = append(errs, fmt.Errorf("  command %q: %s", cmd.Name, line))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readConfig reads the JSON plugin config in path, nil if path is empty.
+func readConfig(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	config, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+	if !json.Valid(config) {
+		return nil, fmt.Errorf("config file %s is not valid JSON", path)
+	}
+	return config, nil
+}
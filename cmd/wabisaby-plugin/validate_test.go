@@ -0,0 +1,68 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/wabisaby/wabisaby-plugin-sdk"
+)
+
+func TestValidateCommands(t *testing.T) {
+	valid := []sdk.CommandMetadata{{Name: "search"}, {Name: "download"}}
+	if err := validateCommands(valid); err != nil {
+		t.Fatalf("valid commands: %v", err)
+	}
+
+	err := validateCommands([]sdk.CommandMetadata{{Name: "search"}, {Name: "search"}})
+	if err == nil || !strings.Contains(err.Error(), `duplicate command "search"`) {
+		t.Fatalf("duplicate commands: error = %v", err)
+	}
+}
+
+func TestReadConfig(t *testing.T) {
+	if config, err := readConfig(""); config != nil || err != nil {
+		t.Fatalf("readConfig(\"\") = %q, %v, want no config", config, err)
+	}
+
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "config.json")
+	invalid := filepath.Join(dir, "invalid.json")
+	if err := os.WriteFile(valid, []byte(`{"quality": "high"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte(`{quality`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if config, err := readConfig(valid); err != nil || string(config) != `{"quality": "high"}` {
+		t.Errorf("readConfig(valid) = %q, %v", config, err)
+	}
+	if _, err := readConfig(invalid); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("readConfig(invalid) error = %v", err)
+	}
+	if _, err := readConfig(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("readConfig(missing) succeeded")
+	}
+}
+
+func TestInvokeArguments(t *testing.T) {
+	if err := runInvoke(nil); err == nil || !strings.Contains(err.Error(), "command name required") {
+		t.Errorf("invoke without a command: error = %v", err)
+	}
+	if err := runInvoke([]string{"search", "{not json"}); err == nil || !strings.Contains(err.Error(), "not valid JSON") {
+		t.Errorf("invoke with an invalid argument: error = %v", err)
+	}
+}
@@ -11,6 +11,11 @@
 
 package sdk
 
+import (
+	"errors"
+	"fmt"
+)
+
 // ParamType represents the type of a command parameter.
 type ParamType string
 
@@ -24,36 +29,99 @@ const (
 	ParamTypeArray  ParamType = "array"
 )
 
+// knownParamTypes are the parameter types accepted in command metadata.
+var knownParamTypes = map[ParamType]bool{
+	ParamTypeString: true,
+	ParamTypeInt:    true,
+	ParamTypeFloat:  true,
+	ParamTypeBool:   true,
+	ParamTypeObject: true,
+	ParamTypeArray:  true,
+}
+
 // CommandMetadata describes a command with its parameters and return type.
 type CommandMetadata struct {
-	Name        string
-	Description string
-	Parameters  []ParameterMetadata
-	ReturnType  *ReturnTypeMetadata
-	Examples    []CommandExample
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Parameters  []ParameterMetadata `json:"parameters,omitempty"`
+	ReturnType  *ReturnTypeMetadata `json:"return_type,omitempty"`
+	Examples    []CommandExample    `json:"examples,omitempty"`
+
+	// TimeoutMs is the timeout applied when the host gives none, zero if unset.
+	TimeoutMs int64 `json:"timeout_ms,omitempty"`
+
+	// MaxTimeoutMs caps any timeout of the command, zero if unset.
+	MaxTimeoutMs int64 `json:"max_timeout_ms,omitempty"`
+
+	// Async is set for commands returning a JobHandle, see WithAsync.
+	Async bool `json:"async,omitempty"`
+
+	middleware       []Middleware // set by WithMiddleware
+	concurrencyLimit int          // set by WithConcurrencyLimit
+	concurrencyQueue int          // set by WithConcurrencyQueue
+}
+
+// Validate checks that the command metadata is complete and consistent.
+func (m CommandMetadata) Validate() error {
+	var errs []error
+	if m.Name == "" {
+		errs = append(errs, fmt.Errorf("name is required"))
+	}
+
+	seen := make(map[string]bool, len(m.Parameters))
+	for i, param := range m.Parameters {
+		if param.Name == "" {
+			errs = append(errs, fmt.Errorf("parameter %d has no name", i))
+		} else if seen[param.Name] {
+			errs = append(errs, fmt.Errorf("duplicate parameter %q", param.Name))
+		}
+		seen[param.Name] = true
+		if !knownParamTypes[param.Type] {
+			errs = append(errs, fmt.Errorf("parameter %q has unknown type %q", param.Name, param.Type))
+		}
+		if param.Required && param.Default != nil {
+			errs = append(errs, fmt.Errorf("required parameter %q has a default", param.Name))
+		}
+	}
+
+	if m.ReturnType != nil {
+		for _, field := range sortedKeys(m.ReturnType.Schema) {
+			if paramType := m.ReturnType.Schema[field]; !knownParamTypes[paramType] {
+				errs = append(errs, fmt.Errorf("return field %q has unknown type %q", field, paramType))
+			}
+		}
+	}
+
+	if m.TimeoutMs < 0 || m.MaxTimeoutMs < 0 {
+		errs = append(errs, fmt.Errorf("timeouts must not be negative"))
+	}
+	if m.MaxTimeoutMs > 0 && m.TimeoutMs > m.MaxTimeoutMs {
+		errs = append(errs, fmt.Errorf("timeout %dms exceeds max timeout %dms", m.TimeoutMs, m.MaxTimeoutMs))
+	}
+	return errors.Join(errs...)
 }
 
 // ParameterMetadata describes a command parameter.
 type ParameterMetadata struct {
-	Name        string
-	Type        ParamType
-	Description string
-	Required    bool
-	Default     interface{}
+	Name        string      `json:"name"`
+	Type        ParamType   `json:"type"`
+	Description string      `json:"description,omitempty"`
+	Required    bool        `json:"required"`
+	Default     interface{} `json:"default,omitempty"`
 }
 
 // ReturnTypeMetadata describes the return type of a command.
 type ReturnTypeMetadata struct {
-	Name        string
-	Description string
-	Schema      map[string]ParamType
+	Name        string               `json:"name"`
+	Description string               `json:"description,omitempty"`
+	Schema      map[string]ParamType `json:"schema,omitempty"`
 }
 
 // CommandExample provides a usage example for a command.
 type CommandExample struct {
-	Description string
-	Args        interface{}
-	Result      interface{}
+	Description string      `json:"description,omitempty"`
+	Args        interface{} `json:"args,omitempty"`
+	Result      interface{} `json:"result,omitempty"`
 }
 
 // CommandOption is a functional option for configuring command metadata.
@@ -0,0 +1,228 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"container/list"
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// QueueWaitTrailer is the gRPC trailer reporting how long a command waited
+// for a concurrency slot, in milliseconds. It is set on every synchronous
+// command and is the supported way to read the queue wait: the
+// ExecuteCommandResponse of protos v0.0.1 has no field for it. Built with
+// the wabisaby_protos_next tag, the SDK also sets the response's QueueWaitMs.
+const QueueWaitTrailer = "x-wabisaby-queue-wait-ms"
+
+// WithConcurrencyLimit limits how many calls of the command run at once,
+// across all tenants. Calls over the limit fail with RESOURCE_EXHAUSTED
+// unless a queue is configured with WithConcurrencyQueue.
+func WithConcurrencyLimit(n int) CommandOption {
+	return func(m *CommandMetadata) {
+		m.concurrencyLimit = n
+	}
+}
+
+// WithConcurrencyQueue lets up to size calls over the command's concurrency
+// limit wait, in FIFO order, for a running call to finish. Calls beyond the
+// queue fail with RESOURCE_EXHAUSTED.
+func WithConcurrencyQueue(size int) CommandOption {
+	return func(m *CommandMetadata) {
+		m.concurrencyQueue = size
+	}
+}
+
+// WithTenantConcurrencyLimit limits how many commands of a single tenant run
+// at once. Up to queue calls over the limit wait in FIFO order; with a queue
+// of zero they fail fast with RESOURCE_EXHAUSTED.
+func WithTenantConcurrencyLimit(limit, queue int) ServeOption {
+	return func(c *serveConfig) {
+		c.tenantConcurrency = limit
+		c.tenantQueue = queue
+	}
+}
+
+// limiter bounds concurrent calls, queueing callers over the limit in FIFO order.
+type limiter struct {
+	mu      sync.Mutex
+	limit   int
+	queue   int
+	active  int
+	waiters list.List // of chan struct{}, closed when a slot is handed over
+
+	users int // calls holding the limiter, guarded by concurrencyLimits.mu
+}
+
+// newLimiter creates a limiter running limit calls at once and queueing up
+// to queue more.
+func newLimiter(limit, queue int) *limiter {
+	return &limiter{limit: limit, queue: queue}
+}
+
+// acquire waits for a slot and returns the function releasing it.
+// Returns a RESOURCE_EXHAUSTED error if the queue is full, or if ctx is done
+// before a slot is free.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	l.mu.Lock()
+	if l.active < l.limit && l.waiters.Len() == 0 {
+		l.active++
+		l.mu.Unlock()
+		return l.release, nil
+	}
+	if l.waiters.Len() >= l.queue {
+		l.mu.Unlock()
+		return nil, ResourceExhausted("concurrency limit of %d reached", l.limit)
+	}
+	ready := make(chan struct{})
+	elem := l.waiters.PushBack(ready)
+	l.mu.Unlock()
+
+	select {
+	case <-ready:
+		return l.release, nil
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	select {
+	case <-ready:
+		// The slot was handed over while giving up, pass it on
+		l.mu.Unlock()
+		l.release()
+	default:
+		l.waiters.Remove(elem)
+		l.mu.Unlock()
+	}
+	return nil, ResourceExhausted("gave up waiting for a concurrency slot").WithCause(ctx.Err())
+}
+
+// release frees a slot, handing it to the oldest waiter if any.
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if front := l.waiters.Front(); front != nil {
+		l.waiters.Remove(front)
+		close(front.Value.(chan struct{}))
+		return
+	}
+	l.active--
+}
+
+// concurrencyLimits holds the per-tenant and per-command limiters of a server.
+// Limiters are created on first use and evicted once no call holds them, so
+// idle tenants do not accumulate.
+type concurrencyLimits struct {
+	mu          sync.Mutex
+	tenantLimit int
+	tenantQueue int
+	tenants     map[uuid.UUID]*limiter
+	commands    map[string]*limiter
+}
+
+// newConcurrencyLimits creates the limiters of a server. A tenant limit of
+// zero leaves tenants unbounded.
+func newConcurrencyLimits(tenantLimit, tenantQueue int) *concurrencyLimits {
+	return &concurrencyLimits{
+		tenantLimit: tenantLimit,
+		tenantQueue: tenantQueue,
+		tenants:     make(map[uuid.UUID]*limiter),
+		commands:    make(map[string]*limiter),
+	}
+}
+
+// acquire waits for a slot of the tenant, then of the command, and returns
+// the function releasing both along with the time spent waiting.
+// The tenant slot is taken first so that a tenant over its limit never
+// occupies the command's queue.
+func (c *concurrencyLimits) acquire(ctx context.Context, tenantID uuid.UUID, cmd CommandMetadata) (func(), time.Duration, error) {
+	start := time.Now()
+	tenant, command := c.hold(tenantID, cmd)
+
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+		c.drop(tenantID, tenant, cmd.Name, command)
+	}
+
+	for _, l := range []*limiter{tenant, command} {
+		if l == nil {
+			continue
+		}
+		rel, err := l.acquire(ctx)
+		if err != nil {
+			release()
+			return nil, time.Since(start), err
+		}
+		releases = append(releases, rel)
+	}
+	return release, time.Since(start), nil
+}
+
+// hold returns the limiters of the tenant and the command, nil where
+// unbounded, counting the caller as a user of each until drop.
+func (c *concurrencyLimits) hold(tenantID uuid.UUID, cmd CommandMetadata) (tenant, command *limiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.tenantLimit > 0 {
+		tenant = c.tenants[tenantID]
+		if tenant == nil {
+			tenant = newLimiter(c.tenantLimit, c.tenantQueue)
+			c.tenants[tenantID] = tenant
+		}
+		tenant.users++
+	}
+	if cmd.concurrencyLimit > 0 {
+		command = c.commands[cmd.Name]
+		if command == nil {
+			command = newLimiter(cmd.concurrencyLimit, cmd.concurrencyQueue)
+			c.commands[cmd.Name] = command
+		}
+		command.users++
+	}
+	return tenant, command
+}
+
+// drop ends the caller's hold on the limiters, evicting those no call holds.
+// A limiter without users has no running or queued calls.
+func (c *concurrencyLimits) drop(tenantID uuid.UUID, tenant *limiter, name string, command *limiter) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if tenant != nil {
+		tenant.users--
+		if tenant.users == 0 {
+			delete(c.tenants, tenantID)
+		}
+	}
+	if command != nil {
+		command.users--
+		if command.users == 0 {
+			delete(c.commands, name)
+		}
+	}
+}
+
+// setQueueWaitTrailer reports the queue wait of a call in its gRPC trailer.
+func setQueueWaitTrailer(ctx context.Context, wait time.Duration) {
+	_ = grpc.SetTrailer(ctx, metadata.Pairs(QueueWaitTrailer, strconv.FormatInt(wait.Milliseconds(), 10)))
+}
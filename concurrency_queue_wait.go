@@ -0,0 +1,26 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// withQueueWait reports the queue wait of a call in its response.
+func withQueueWait(resp *pluginpb.ExecuteCommandResponse, wait time.Duration) *pluginpb.ExecuteCommandResponse {
+	resp.QueueWaitMs = wait.Milliseconds()
+	return resp
+}
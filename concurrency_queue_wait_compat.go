@@ -0,0 +1,30 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build !wabisaby_protos_next
+
+package sdk
+
+import (
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// The ExecuteCommandResponse of the released protos has no queue wait, which
+// is then only reported in the QueueWaitTrailer. Build with the
+// wabisaby_protos_next tag against protos that have it to report it in the
+// response.
+
+// withQueueWait returns resp unchanged.
+func withQueueWait(resp *pluginpb.ExecuteCommandResponse, wait time.Duration) *pluginpb.ExecuteCommandResponse {
+	return resp
+}
@@ -0,0 +1,45 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestQueueWaitReportedInResponse(t *testing.T) {
+	plugin, started, release := blockingPlugin(t)
+	must(t, plugin.RegisterCommand("ping", func(ctx *Context) (interface{}, error) {
+		return "pong", nil
+	}, WithConcurrencyLimit(1), WithConcurrencyQueue(1)))
+	server := newTestServer(t, plugin, WithTenantConcurrencyLimit(1, 1))
+	target := newTestTarget()
+
+	go func() { _ = target.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+	go func() {
+		time.Sleep(30 * time.Millisecond)
+		close(release)
+	}()
+
+	resp, err := server.ExecuteCommand(testContext(t), target.request(t, "ping"))
+	must(t, err)
+	if resp.GetError() != nil {
+		t.Fatalf("ping failed: %v", resp.GetError())
+	}
+	if resp.QueueWaitMs < 20 {
+		t.Fatalf("QueueWaitMs = %d, want the time spent queued behind the blocked call", resp.QueueWaitMs)
+	}
+}
@@ -0,0 +1,154 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// acquireAsync acquires a slot of l in a goroutine once the previous
+// waiters queued, and sends its index to order when it gets the slot.
+func acquireAsync(t *testing.T, l *limiter, index int, order chan<- int) {
+	go func() {
+		release, err := l.acquire(context.Background())
+		if err != nil {
+			t.Errorf("waiter %d: %v", index, err)
+			return
+		}
+		order <- index
+		release()
+	}()
+	waitFor(t, func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.waiters.Len() == index+1
+	})
+}
+
+// waitFor polls cond until it holds or the test times out.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within 5s")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestLimiterQueuesInFIFOOrder(t *testing.T) {
+	l := newLimiter(1, 3)
+	release, err := l.acquire(context.Background())
+	must(t, err)
+
+	order := make(chan int, 3)
+	for i := 0; i < 3; i++ {
+		acquireAsync(t, l, i, order)
+	}
+	release()
+
+	for want := 0; want < 3; want++ {
+		if got := <-order; got != want {
+			t.Fatalf("waiter %d got a slot, want waiter %d", got, want)
+		}
+	}
+}
+
+func TestLimiterFailsFastWhenQueueIsFull(t *testing.T) {
+	l := newLimiter(1, 0)
+	release, err := l.acquire(context.Background())
+	must(t, err)
+	defer release()
+
+	_, err = l.acquire(context.Background())
+	if code := errorCode(err); code != CodeResourceExhausted {
+		t.Fatalf("error = %v, want %s", err, CodeResourceExhausted)
+	}
+}
+
+func TestLimiterWaiterGivesUp(t *testing.T) {
+	l := newLimiter(1, 1)
+	release, err := l.acquire(context.Background())
+	must(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.acquire(ctx); errorCode(err) != CodeResourceExhausted {
+		t.Fatalf("error = %v, want %s", err, CodeResourceExhausted)
+	}
+
+	release()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active != 0 || l.waiters.Len() != 0 {
+		t.Fatalf("limiter has %d active calls and %d waiters, want none", l.active, l.waiters.Len())
+	}
+}
+
+func TestConcurrencyLimitsEvictIdleLimiters(t *testing.T) {
+	limits := newConcurrencyLimits(2, 0)
+	cmd := CommandMetadata{Name: "search"}
+	WithConcurrencyLimit(1)(&cmd)
+
+	var releases []func()
+	for i := 0; i < 50; i++ {
+		release, _, err := limits.acquire(context.Background(), uuid.New(), CommandMetadata{Name: "ping"})
+		must(t, err)
+		releases = append(releases, release)
+	}
+	release, _, err := limits.acquire(context.Background(), uuid.New(), cmd)
+	must(t, err)
+	if _, _, err := limits.acquire(context.Background(), uuid.New(), cmd); errorCode(err) != CodeResourceExhausted {
+		t.Fatalf("second call of the command: error = %v, want %s", err, CodeResourceExhausted)
+	}
+	releases = append(releases, release)
+
+	for _, release := range releases {
+		release()
+	}
+	limits.mu.Lock()
+	defer limits.mu.Unlock()
+	if len(limits.tenants) != 0 || len(limits.commands) != 0 {
+		t.Fatalf("%d tenant and %d command limiters left, want none", len(limits.tenants), len(limits.commands))
+	}
+}
+
+func TestTenantConcurrencyLimitIsolatesTenants(t *testing.T) {
+	plugin, started, release := blockingPlugin(t)
+	server := newTestServer(t, plugin, WithTenantConcurrencyLimit(1, 0))
+	busy, other := newTestTarget(), newTestTarget()
+
+	results := make(chan *pluginpb.PluginError, 2)
+	go func() { results <- busy.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+
+	if perr := busy.execute(t, server, testContext(t), nil, "block"); perr.GetCode() != string(CodeResourceExhausted) {
+		t.Fatalf("second call of the tenant: error = %v, want %s", perr, CodeResourceExhausted)
+	}
+
+	// Another tenant is not limited by the busy one
+	go func() { results <- other.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+	close(release)
+	for i := 0; i < 2; i++ {
+		if perr := <-results; perr != nil {
+			t.Fatalf("blocked call failed: %v", perr)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// configVersionLength is the number of hex characters of the config hash
+// used as the config version.
+const configVersionLength = 16
+
+// tenantConfig is the decoded configuration of a tenant/plugin pair.
+type tenantConfig struct {
+	data    map[string]interface{}
+	version string
+}
+
+// newTenantConfig creates a tenant config from its decoded and raw forms.
+// The version is derived from the raw config, so identical configs share a version.
+func newTenantConfig(data map[string]interface{}, raw []byte) *tenantConfig {
+	sum := sha256.Sum256(raw)
+	return &tenantConfig{
+		data:    data,
+		version: hex.EncodeToString(sum[:])[:configVersionLength],
+	}
+}
+
+// copy returns a deep copy of the config data, so that contexts sharing the
+// cached config cannot modify it for each other.
+func (c *tenantConfig) copy() map[string]interface{} {
+	if c.data == nil {
+		return nil
+	}
+	return copyConfigValue(c.data).(map[string]interface{})
+}
+
+// copyConfigValue deep copies a JSON-decoded value.
+func copyConfigValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			copied[key] = copyConfigValue(elem)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, elem := range v {
+			copied[i] = copyConfigValue(elem)
+		}
+		return copied
+	default:
+		return v
+	}
+}
+
+// configCache keeps the latest config of each tenant/plugin pair.
+type configCache struct {
+	mu      sync.RWMutex
+	entries map[instanceKey]*tenantConfig
+}
+
+// newConfigCache creates a new config cache.
+func newConfigCache() *configCache {
+	return &configCache{
+		entries: make(map[instanceKey]*tenantConfig),
+	}
+}
+
+// get returns the cached config for the key, or nil if none is cached.
+func (c *configCache) get(key instanceKey) *tenantConfig {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries[key]
+}
+
+// put caches the config for the key, replacing any previous config.
+func (c *configCache) put(key instanceKey, config *tenantConfig) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[key] = config
+}
+
+// remove invalidates the cached config for the key.
+func (c *configCache) remove(key instanceKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+// configPlugin reports and modifies the config its commands see.
+func configPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("config", func(ctx *Context) (interface{}, error) {
+		return map[string]interface{}{
+			"version": ctx.Config.Version(),
+			"limit":   ctx.Config.GetInt("limit"),
+			"nested":  ctx.Config.Get("nested"),
+		}, nil
+	}))
+	must(t, plugin.RegisterCommand("mutate", func(ctx *Context) (interface{}, error) {
+		if nested, ok := ctx.Config.Get("nested").(map[string]interface{}); ok {
+			nested["key"] = "changed"
+		}
+		ctx.GetSession().Config["limit"] = 0
+		return nil, nil
+	}))
+	return plugin
+}
+
+type configResult struct {
+	Version string                 `json:"version"`
+	Limit   int                    `json:"limit"`
+	Nested  map[string]interface{} `json:"nested"`
+}
+
+func TestConfigIsCachedPerTenant(t *testing.T) {
+	server := newTestServer(t, configPlugin(t))
+	alice, bob := newTestTarget(), newTestTarget()
+	config := map[string]interface{}{"limit": 5, "nested": map[string]interface{}{"key": "value"}}
+	alice.enable(t, server, config)
+	bob.enable(t, server, config)
+
+	var got, other configResult
+	alice.run(t, server, &got, "config")
+	bob.run(t, server, &other, "config")
+	if got.Limit != 5 || got.Nested["key"] != "value" || got.Version == "" {
+		t.Fatalf("command saw config %+v", got)
+	}
+	if other.Version != got.Version {
+		t.Fatalf("identical configs have versions %s and %s", got.Version, other.Version)
+	}
+
+	alice.enable(t, server, map[string]interface{}{"limit": 6})
+	alice.run(t, server, &got, "config")
+	if got.Limit != 6 || got.Version == other.Version {
+		t.Fatalf("command saw config %+v after the config changed", got)
+	}
+}
+
+func TestConfigIsCopiedPerContext(t *testing.T) {
+	server := newTestServer(t, configPlugin(t))
+	target := newTestTarget()
+	target.enable(t, server, map[string]interface{}{"limit": 5, "nested": map[string]interface{}{"key": "value"}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := target.execute(t, server, context.Background(), nil, "mutate"); err != nil {
+				t.Errorf("mutate failed: %s", err.Message)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var got configResult
+	target.run(t, server, &got, "config")
+	if got.Limit != 5 || got.Nested["key"] != "value" {
+		t.Fatalf("cached config modified by a command: %+v", got)
+	}
+}
+
+func TestEnableWithoutConfigClearsCachedConfig(t *testing.T) {
+	server := newTestServer(t, configPlugin(t))
+	target := newTestTarget()
+	target.enable(t, server, map[string]interface{}{"limit": 5})
+	target.enable(t, server, nil)
+
+	var got configResult
+	target.run(t, server, &got, "config")
+	if got.Limit != 0 || got.Version != "" {
+		t.Fatalf("command saw stale config %+v", got)
+	}
+}
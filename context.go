@@ -15,6 +15,7 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/hashicorp/go-hclog"
 	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
 	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
 )
@@ -53,11 +54,34 @@ type Context struct {
 	PluginID     uuid.UUID
 	Config       *ConfigAccessor
 
+	// ProtocolVersion is the protocol version negotiated with the host,
+	// zero if the context was not created by a served plugin.
+	ProtocolVersion int
+
+	// Request describes the host request that triggered the call.
+	// Its deadline is the deadline of the embedded context.
+	Request *RequestInfo
+
+	// Reporter of the command's progress, nil outside commands
+	progress ProgressReporter
+
+	// Logger of the plugin process, nil if the context was not created by a server
+	processLogger hclog.Logger
+
 	// Backward compatibility - use GetStub() and GetSession() for access
 	stub    *stub.PluginStub
 	session *PluginSession
 }
 
+// localLogger returns the logger of the plugin process, which does not go
+// through the capabilities service.
+func (c *Context) localLogger() hclog.Logger {
+	if c == nil || c.processLogger == nil {
+		return hclog.Default()
+	}
+	return c.processLogger.With("tenant_id", c.TenantID.String(), "plugin_id", c.PluginID.String())
+}
+
 // GetStub returns the plugin stub with semantically grouped API services.
 func (c *Context) GetStub() *stub.PluginStub {
 	return c.stub
@@ -103,6 +127,9 @@ func NewContext(
 		Config:   config,
 	}
 
+	// Read the request info, passing the request ID on to capability calls
+	ctx, request := withRequestInfo(ctx)
+
 	// Create context with direct accessors
 	pluginCtx := &Context{
 		Context: ctx,
@@ -119,6 +146,7 @@ func NewContext(
 		TenantID:     tenantID,
 		PluginID:     pluginID,
 		Config:       NewConfigAccessor(config),
+		Request:      request,
 	}
 
 	// Create logger with context reference
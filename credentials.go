@@ -0,0 +1,247 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Environment variables configuring the security of the capabilities connection.
+const (
+	// EnvCapabilitiesTLSCA is the path to a PEM CA bundle used to verify the
+	// capabilities service. Setting it enables TLS.
+	EnvCapabilitiesTLSCA = "WABISABY_CAPABILITIES_TLS_CA"
+
+	// EnvCapabilitiesTLSCert and EnvCapabilitiesTLSKey are the paths to the
+	// PEM client certificate and key presented to the capabilities service
+	// (mTLS). Setting them enables TLS.
+	EnvCapabilitiesTLSCert = "WABISABY_CAPABILITIES_TLS_CERT"
+	EnvCapabilitiesTLSKey  = "WABISABY_CAPABILITIES_TLS_KEY"
+
+	// EnvCapabilitiesTLSServerName is the server name expected in the
+	// capabilities service certificate.
+	EnvCapabilitiesTLSServerName = "WABISABY_CAPABILITIES_TLS_SERVER_NAME"
+
+	// EnvCapabilitiesToken is a per-process bearer token attached to every
+	// capability call.
+	EnvCapabilitiesToken = "WABISABY_CAPABILITIES_TOKEN"
+
+	// EnvCapabilitiesRequireTLS set to "true" makes startup fail unless TLS
+	// is configured for the capabilities connection.
+	EnvCapabilitiesRequireTLS = "WABISABY_CAPABILITIES_REQUIRE_TLS"
+)
+
+// capabilitiesSecurity configures the security of the capabilities connection.
+type capabilitiesSecurity struct {
+	tlsConfig  *tls.Config
+	caFile     string
+	certFile   string
+	keyFile    string
+	serverName string
+	token      string
+	requireTLS bool
+}
+
+// securityFromEnv reads the capabilities connection security from the environment.
+// Returns an error if EnvCapabilitiesRequireTLS is set but not a boolean.
+func securityFromEnv() (capabilitiesSecurity, error) {
+	var requireTLS bool
+	if value := os.Getenv(EnvCapabilitiesRequireTLS); value != "" {
+		var err error
+		if requireTLS, err = strconv.ParseBool(value); err != nil {
+			return capabilitiesSecurity{}, fmt.Errorf("invalid %s %q: want true or false", EnvCapabilitiesRequireTLS, value)
+		}
+	}
+	return capabilitiesSecurity{
+		caFile:     os.Getenv(EnvCapabilitiesTLSCA),
+		certFile:   os.Getenv(EnvCapabilitiesTLSCert),
+		keyFile:    os.Getenv(EnvCapabilitiesTLSKey),
+		serverName: os.Getenv(EnvCapabilitiesTLSServerName),
+		token:      os.Getenv(EnvCapabilitiesToken),
+		requireTLS: requireTLS,
+	}, nil
+}
+
+// dialOptions returns the credential dial options for the capabilities
+// connection to addr. Returns an error if TLS is required but not configured,
+// if a token would be sent to a remote address without TLS, or if the
+// configured certificates cannot be loaded.
+func (c capabilitiesSecurity) dialOptions(addr string) ([]grpc.DialOption, error) {
+	tlsConfig, err := c.buildTLSConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	if tlsConfig == nil && c.requireTLS {
+		return nil, fmt.Errorf("capabilities service requires TLS but no TLS configuration was provided")
+	}
+	if tlsConfig == nil && c.token != "" && !isLoopbackAddr(addr) {
+		return nil, fmt.Errorf("refusing to send the capabilities token to %s without TLS", addr)
+	}
+
+	var opts []grpc.DialOption
+	if tlsConfig != nil {
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
+	} else {
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	if c.token != "" {
+		opts = append(opts, grpc.WithPerRPCCredentials(bearerToken{
+			token:  c.token,
+			secure: tlsConfig != nil,
+		}))
+	}
+	return opts, nil
+}
+
+// buildTLSConfig returns the TLS configuration, or nil if TLS is not configured.
+func (c capabilitiesSecurity) buildTLSConfig() (*tls.Config, error) {
+	if c.tlsConfig != nil {
+		tlsConfig := c.tlsConfig.Clone()
+		if c.serverName != "" {
+			tlsConfig.ServerName = c.serverName
+		}
+		return tlsConfig, nil
+	}
+
+	if c.caFile == "" && c.certFile == "" && c.keyFile == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: c.serverName,
+	}
+
+	if c.caFile != "" {
+		caPEM, err := os.ReadFile(c.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read capabilities CA bundle: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("capabilities CA bundle %s contains no certificates", c.caFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if c.certFile != "" || c.keyFile != "" {
+		if c.certFile == "" || c.keyFile == "" {
+			return nil, fmt.Errorf("both %s and %s must be set for mTLS", EnvCapabilitiesTLSCert, EnvCapabilitiesTLSKey)
+		}
+		cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load capabilities client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
+
+// isLoopbackAddr reports whether addr is a Unix socket or a loopback address,
+// which is how the host serves capabilities to plugins on the same machine.
+func isLoopbackAddr(addr string) bool {
+	if strings.HasPrefix(addr, "unix:") || strings.HasPrefix(addr, "unix-abstract:") {
+		return true
+	}
+	addr = strings.TrimPrefix(addr, "dns:///")
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// bearerToken attaches a bearer token to every capability call.
+type bearerToken struct {
+	token  string
+	secure bool
+}
+
+// GetRequestMetadata implements credentials.PerRPCCredentials.
+func (t bearerToken) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	return map[string]string{
+		"authorization": "Bearer " + t.token,
+	}, nil
+}
+
+// RequireTransportSecurity implements credentials.PerRPCCredentials.
+// The token is only sent without TLS over loopback connections.
+func (t bearerToken) RequireTransportSecurity() bool {
+	return t.secure
+}
+
+// WithCapabilitiesTLS sets the TLS configuration of the capabilities
+// connection, taking precedence over the TLS environment variables.
+func WithCapabilitiesTLS(tlsConfig *tls.Config) ServeOption {
+	return func(c *serveConfig) {
+		c.security.tlsConfig = tlsConfig
+	}
+}
+
+// WithCapabilitiesTLSFiles sets the CA bundle, client certificate and client
+// key files of the capabilities connection. Empty paths are ignored, keeping
+// the path from the environment; the client certificate and key are only
+// needed for mTLS.
+func WithCapabilitiesTLSFiles(caFile, certFile, keyFile string) ServeOption {
+	return func(c *serveConfig) {
+		if caFile != "" {
+			c.security.caFile = caFile
+		}
+		if certFile != "" {
+			c.security.certFile = certFile
+		}
+		if keyFile != "" {
+			c.security.keyFile = keyFile
+		}
+	}
+}
+
+// WithCapabilitiesServerName sets the server name expected in the
+// capabilities service certificate.
+func WithCapabilitiesServerName(serverName string) ServeOption {
+	return func(c *serveConfig) {
+		c.security.serverName = serverName
+	}
+}
+
+// WithCapabilitiesToken sets the bearer token attached to every capability call.
+func WithCapabilitiesToken(token string) ServeOption {
+	return func(c *serveConfig) {
+		c.security.token = token
+	}
+}
+
+// WithRequireCapabilitiesTLS makes NewServer fail unless TLS is configured
+// for the capabilities connection.
+func WithRequireCapabilitiesTLS() ServeOption {
+	return func(c *serveConfig) {
+		c.security.requireTLS = true
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+)
+
+// testPKI is a locally generated CA with a server and a client certificate.
+type testPKI struct {
+	caFile     string
+	certFile   string // client certificate
+	keyFile    string // client key
+	serverCert tls.Certificate
+	pool       *x509.CertPool
+}
+
+// newTestPKI generates a CA and certificates signed by it into a temporary
+// directory. The server certificate is valid for localhost.
+func newTestPKI(t *testing.T) testPKI {
+	t.Helper()
+
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	must(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Minute),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	must(t, err)
+	ca, err := x509.ParseCertificate(caDER)
+	must(t, err)
+
+	issue := func(serial int64, usage x509.ExtKeyUsage) ([]byte, *ecdsa.PrivateKey) {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		must(t, err)
+		template := &x509.Certificate{
+			SerialNumber: big.NewInt(serial),
+			Subject:      pkix.Name{CommonName: "localhost"},
+			DNSNames:     []string{"localhost"},
+			IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+			NotBefore:    time.Now().Add(-time.Minute),
+			NotAfter:     time.Now().Add(time.Hour),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}
+		der, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+		must(t, err)
+		return der, key
+	}
+
+	dir := t.TempDir()
+	writePEM := func(name, blockType string, data []byte) string {
+		path := filepath.Join(dir, name)
+		must(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: data}), 0o600))
+		return path
+	}
+
+	serverDER, serverKey := issue(2, x509.ExtKeyUsageServerAuth)
+	serverKeyDER, err := x509.MarshalECPrivateKey(serverKey)
+	must(t, err)
+	serverCert, err := tls.X509KeyPair(
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: serverDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: serverKeyDER}),
+	)
+	must(t, err)
+
+	clientDER, clientKey := issue(3, x509.ExtKeyUsageClientAuth)
+	clientKeyDER, err := x509.MarshalECPrivateKey(clientKey)
+	must(t, err)
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca)
+	return testPKI{
+		caFile:     writePEM("ca.pem", "CERTIFICATE", caDER),
+		certFile:   writePEM("client.pem", "CERTIFICATE", clientDER),
+		keyFile:    writePEM("client-key.pem", "EC PRIVATE KEY", clientKeyDER),
+		serverCert: serverCert,
+		pool:       pool,
+	}
+}
+
+// serveMTLS serves the standard gRPC health service over mTLS and records
+// the authorization metadata of each call.
+func (p testPKI) serveMTLS(t *testing.T) (addr string, authorizations func() []string) {
+	t.Helper()
+
+	var mu sync.Mutex
+	var seen []string
+	record := func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		mu.Lock()
+		seen = append(seen, md.Get("authorization")...)
+		mu.Unlock()
+		return handler(ctx, req)
+	}
+
+	creds := credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{p.serverCert},
+		ClientCAs:    p.pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	addr = serveHealth(t, grpc.Creds(creds), grpc.UnaryInterceptor(record))
+	return addr, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), seen...)
+	}
+}
+
+func TestCapabilitiesMTLSWithToken(t *testing.T) {
+	pki := newTestPKI(t)
+	addr, authorizations := pki.serveMTLS(t)
+
+	server, err := NewServer(&BasePlugin{},
+		WithCapabilitiesAddr(addr),
+		WithCapabilitiesTLSFiles(pki.caFile, pki.certFile, pki.keyFile),
+		WithCapabilitiesServerName("localhost"),
+		WithCapabilitiesToken("secret"),
+		WithRequireCapabilitiesTLS(),
+	)
+	must(t, err)
+	defer server.Close()
+
+	must(t, checkHealthService(t, server.capabilitiesConn))
+	if got := authorizations(); len(got) != 1 || got[0] != "Bearer secret" {
+		t.Fatalf("authorization metadata = %v, want the bearer token", got)
+	}
+}
+
+func TestCapabilitiesMTLSRejectsMissingClientCertificate(t *testing.T) {
+	pki := newTestPKI(t)
+	addr, _ := pki.serveMTLS(t)
+
+	server, err := NewServer(&BasePlugin{},
+		WithCapabilitiesAddr(addr),
+		WithCapabilitiesTLSFiles(pki.caFile, "", ""),
+		WithCapabilitiesServerName("localhost"),
+	)
+	must(t, err)
+	defer server.Close()
+
+	if err := checkHealthService(t, server.capabilitiesConn); err == nil {
+		t.Fatal("call without a client certificate succeeded")
+	}
+}
+
+func TestCapabilitiesTLSFilesKeepEnvironmentPaths(t *testing.T) {
+	t.Setenv(EnvCapabilitiesTLSCA, "/etc/wabisaby/ca.pem")
+
+	config, err := newServeConfig(WithCapabilitiesTLSFiles("", "client.pem", "client-key.pem"))
+	must(t, err)
+	if config.security.caFile != "/etc/wabisaby/ca.pem" {
+		t.Errorf("caFile = %q, want the environment path", config.security.caFile)
+	}
+	if config.security.certFile != "client.pem" || config.security.keyFile != "client-key.pem" {
+		t.Errorf("client files = %q, %q, want the option paths", config.security.certFile, config.security.keyFile)
+	}
+}
+
+func TestCapabilitiesRequireTLSFromEnvironment(t *testing.T) {
+	t.Setenv(EnvCapabilitiesRequireTLS, "true")
+	if _, err := NewServer(&BasePlugin{}, WithCapabilitiesAddr("127.0.0.1:1")); err == nil {
+		t.Fatal("NewServer without TLS succeeded, want an error")
+	}
+
+	t.Setenv(EnvCapabilitiesRequireTLS, "yes please")
+	_, err := NewServer(&BasePlugin{}, WithCapabilitiesAddr("127.0.0.1:1"))
+	if err == nil || !strings.Contains(err.Error(), EnvCapabilitiesRequireTLS) {
+		t.Fatalf("NewServer with an invalid %s = %v, want an error naming it", EnvCapabilitiesRequireTLS, err)
+	}
+}
+
+func TestCapabilitiesRequireTLS(t *testing.T) {
+	_, err := NewServer(&BasePlugin{}, WithCapabilitiesAddr("127.0.0.1:1"), WithRequireCapabilitiesTLS())
+	if err == nil {
+		t.Fatal("NewServer without TLS succeeded, want an error")
+	}
+}
+
+func TestCapabilitiesTokenWithoutTLS(t *testing.T) {
+	tests := []struct {
+		addr    string
+		wantErr bool
+	}{
+		{addr: "127.0.0.1:50051"},
+		{addr: "localhost:50051"},
+		{addr: "[::1]:50051"},
+		{addr: "unix:///tmp/capabilities.sock"},
+		{addr: "capabilities.internal:50051", wantErr: true},
+		{addr: "10.0.0.5:50051", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			server, err := NewServer(&BasePlugin{}, WithCapabilitiesAddr(tt.addr), WithCapabilitiesToken("secret"))
+			if err == nil {
+				_ = server.Close()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
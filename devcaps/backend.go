@@ -0,0 +1,183 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+// Package devcaps provides an in-process fake of the WabiSaby capabilities
+// service, used to run plugins without the host.
+package devcaps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+)
+
+// DefaultStorageDir is the directory storage is kept in by default,
+// relative to the working directory.
+const DefaultStorageDir = ".wabisaby-dev/storage"
+
+// Fixtures are the songs, users and secrets served by the backend.
+type Fixtures struct {
+	Songs   []map[string]interface{} `json:"songs"`
+	Users   []map[string]interface{} `json:"users"`
+	Secrets map[string]string        `json:"secrets"`
+}
+
+// DefaultFixtures returns the fixtures used when none are given.
+func DefaultFixtures() Fixtures {
+	return Fixtures{
+		Songs: []map[string]interface{}{
+			{"id": "song-1", "title": "Never Gonna Give You Up", "artist": "Rick Astley", "duration": 213},
+			{"id": "song-2", "title": "Bohemian Rhapsody", "artist": "Queen", "duration": 354},
+			{"id": "song-3", "title": "Take On Me", "artist": "a-ha", "duration": 225},
+		},
+		Users: []map[string]interface{}{
+			{"id": "user-1", "name": "Dev User", "roles": []string{"admin"}},
+			{"id": "user-2", "name": "Guest", "roles": []string{}},
+		},
+		Secrets: map[string]string{},
+	}
+}
+
+// LoadFixtures reads fixtures from a JSON file.
+func LoadFixtures(path string) (Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Fixtures{}, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	var fixtures Fixtures
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return Fixtures{}, fmt.Errorf("failed to decode fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// Backend is a fake capabilities service. Storage is kept in files,
+// songs, users and secrets come from fixtures, and logs and notifications
+// are written to the log output. HTTP fetches are performed for real.
+type Backend struct {
+	pluginpb.UnimplementedPluginCapabilitiesServiceServer
+
+	storageDir string
+	fixtures   Fixtures
+	logOutput  io.Writer
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	queues  map[string][]stub.QueueItem // by tenant ID
+	secrets map[string]string
+	nextID  int
+}
+
+// Option configures a Backend.
+type Option func(*Backend)
+
+// WithStorageDir sets the directory storage is kept in.
+// Defaults to DefaultStorageDir.
+func WithStorageDir(dir string) Option {
+	return func(b *Backend) {
+		b.storageDir = dir
+	}
+}
+
+// WithFixtures sets the songs, users and secrets served by the backend.
+// Defaults to DefaultFixtures().
+func WithFixtures(fixtures Fixtures) Option {
+	return func(b *Backend) {
+		b.fixtures = fixtures
+	}
+}
+
+// WithLogOutput sets where plugin logs and notifications are written.
+// Defaults to os.Stderr.
+func WithLogOutput(w io.Writer) Option {
+	return func(b *Backend) {
+		b.logOutput = w
+	}
+}
+
+// WithHTTPClient sets the client performing HTTP fetches.
+// Defaults to http.DefaultClient.
+func WithHTTPClient(client *http.Client) Option {
+	return func(b *Backend) {
+		b.httpClient = client
+	}
+}
+
+// New creates a backend.
+func New(opts ...Option) *Backend {
+	b := &Backend{
+		storageDir: DefaultStorageDir,
+		fixtures:   DefaultFixtures(),
+		logOutput:  os.Stderr,
+		httpClient: http.DefaultClient,
+		queues:     make(map[string][]stub.QueueItem),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	b.secrets = make(map[string]string, len(b.fixtures.Secrets))
+	for key, value := range b.fixtures.Secrets {
+		b.secrets[key] = value
+	}
+	return b
+}
+
+// Start serves the backend over gRPC on addr, such as "127.0.0.1:0", and
+// returns the address it listens on along with a function stopping it.
+func (b *Backend) Start(addr string) (string, func(), error) {
+	if err := os.MkdirAll(b.storageDir, 0o755); err != nil {
+		return "", nil, fmt.Errorf("failed to create storage directory: %w", err)
+	}
+
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to listen: %w", err)
+	}
+
+	server := grpc.NewServer()
+	pluginpb.RegisterPluginCapabilitiesServiceServer(server, b)
+	go func() {
+		_ = server.Serve(lis)
+	}()
+
+	return lis.Addr().String(), server.GracefulStop, nil
+}
+
+// storagePath returns the file a storage key of a tenant and plugin is kept in.
+func (b *Backend) storagePath(tenantID, pluginID, key string) string {
+	return filepath.Join(b.storageDir, tenantID, pluginID, encodeKey(key))
+}
+
+// newID returns a new identifier with the given prefix.
+func (b *Backend) newID(prefix string) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	return fmt.Sprintf("%s-%d", prefix, b.nextID)
+}
+
+// pluginError creates a PluginError reported to the plugin.
+func pluginError(code, format string, args ...interface{}) *pluginpb.PluginError {
+	return &pluginpb.PluginError{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
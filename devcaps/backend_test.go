@@ -0,0 +1,230 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package devcaps
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// newTestBackend creates a backend with temporary storage, writing its
+// logs to the returned buffer.
+func newTestBackend(t *testing.T, opts ...Option) (*Backend, *bytes.Buffer) {
+	t.Helper()
+	var logs bytes.Buffer
+	opts = append([]Option{WithStorageDir(t.TempDir()), WithLogOutput(&logs)}, opts...)
+	return New(opts...), &logs
+}
+
+// queue returns the queue of tenant.
+func queue(t *testing.T, b *Backend, tenant string) []stub.QueueItem {
+	t.Helper()
+	resp, err := b.QueueGet(context.Background(), &pluginpb.QueueGetRequest{TenantId: tenant})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("QueueGet: %v %v", err, resp.Error)
+	}
+	var items []stub.QueueItem
+	if err := json.Unmarshal(resp.QueueData, &items); err != nil {
+		t.Fatalf("decode queue: %v", err)
+	}
+	return items
+}
+
+func TestStorage(t *testing.T) {
+	b, _ := newTestBackend(t)
+	ctx := context.Background()
+	set := func(tenant, key, value string) {
+		t.Helper()
+		resp, err := b.StorageSet(ctx, &pluginpb.StorageSetRequest{TenantId: tenant, PluginId: "p", Key: key, Value: []byte(value)})
+		if err != nil || resp.Error != nil {
+			t.Fatalf("StorageSet(%s): %v %v", key, err, resp.Error)
+		}
+	}
+	set("t1", "songs/../escape", `1`)
+	set("t1", "songs/2", `2`)
+	set("t1", "users/1", `3`)
+	set("t2", "songs/3", `4`)
+
+	get, _ := b.StorageGet(ctx, &pluginpb.StorageGetRequest{TenantId: "t1", PluginId: "p", Key: "songs/../escape"})
+	if string(get.Value) != "1" {
+		t.Fatalf("value = %q, want 1", get.Value)
+	}
+	if _, err := os.Stat(filepath.Join(b.storageDir, "t1", "escape")); !os.IsNotExist(err) {
+		t.Fatalf("key escaped the storage directory: %v", err)
+	}
+
+	keys, _ := b.StorageKeys(ctx, &pluginpb.StorageKeysRequest{TenantId: "t1", PluginId: "p", Prefix: "songs/"})
+	if strings.Join(keys.Keys, ",") != "songs/../escape,songs/2" {
+		t.Fatalf("keys = %v, want the tenant's songs/ keys sorted", keys.Keys)
+	}
+
+	if _, err := b.StorageDelete(ctx, &pluginpb.StorageDeleteRequest{TenantId: "t1", PluginId: "p", Key: "songs/2"}); err != nil {
+		t.Fatal(err)
+	}
+	missing, _ := b.StorageGet(ctx, &pluginpb.StorageGetRequest{TenantId: "t1", PluginId: "p", Key: "songs/2"})
+	if missing.Error.GetCode() != "NOT_FOUND" {
+		t.Fatalf("deleted key: error = %v, want NOT_FOUND", missing.Error)
+	}
+	none, _ := b.StorageKeys(ctx, &pluginpb.StorageKeysRequest{TenantId: "t3", PluginId: "p"})
+	if none.Error != nil || len(none.Keys) != 0 {
+		t.Fatalf("keys of an unknown tenant = %v %v, want none", none.Keys, none.Error)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	b, _ := newTestBackend(t)
+	ctx := context.Background()
+	add := func(song string, position int32) *pluginpb.PluginError {
+		resp, err := b.QueueAdd(ctx, &pluginpb.QueueAddRequest{TenantId: "t", SongData: []byte(song), Position: position})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.Error
+	}
+
+	for _, perr := range []*pluginpb.PluginError{
+		add(`"song-2"`, -1),
+		add(`{"id": "custom", "title": "Custom"}`, 0),
+		add(`"song-1"`, 1),
+	} {
+		if perr != nil {
+			t.Fatalf("QueueAdd: %v", perr)
+		}
+	}
+	if perr := add(`"song-9"`, -1); perr.GetCode() != "NOT_FOUND" {
+		t.Fatalf("adding an unknown song: error = %v, want NOT_FOUND", perr)
+	}
+
+	_, _ = b.QueueReorder(ctx, &pluginpb.QueueReorderRequest{TenantId: "t", FromPosition: 0, ToPosition: 2})
+	_, _ = b.QueueRemove(ctx, &pluginpb.QueueRemoveRequest{TenantId: "t", Position: 0})
+	items := queue(t, b, "t")
+	if len(items) != 2 || items[0].SongID != "song-2" || items[1].SongID != "custom" {
+		t.Fatalf("queue = %+v, want song-2 then custom", items)
+	}
+	for i, item := range items {
+		if item.Position != i {
+			t.Fatalf("item %d has position %d", i, item.Position)
+		}
+	}
+
+	if len(queue(t, b, "other")) != 0 {
+		t.Fatal("queue shared between tenants")
+	}
+	resp, _ := b.QueueReorder(ctx, &pluginpb.QueueReorderRequest{TenantId: "t", FromPosition: 0, ToPosition: 5})
+	if resp.Error.GetCode() != "INVALID_ARGUMENT" {
+		t.Fatalf("reordering outside the queue: error = %v, want INVALID_ARGUMENT", resp.Error)
+	}
+}
+
+func TestSongsAndUsers(t *testing.T) {
+	b, _ := newTestBackend(t)
+	ctx := context.Background()
+
+	search, _ := b.SongSearch(ctx, &pluginpb.SongSearchRequest{Query: "QUEEN", Limit: 5})
+	var songs []map[string]interface{}
+	if err := json.Unmarshal(search.Songs, &songs); err != nil || len(songs) != 1 || songs[0]["id"] != "song-2" {
+		t.Fatalf("search for queen = %s, want song-2", search.Songs)
+	}
+	limited, _ := b.SongSearch(ctx, &pluginpb.SongSearchRequest{Query: "", Limit: 2})
+	if err := json.Unmarshal(limited.Songs, &songs); err != nil || len(songs) != 2 {
+		t.Fatalf("search limited to 2 = %s", limited.Songs)
+	}
+
+	if song, _ := b.SongGet(ctx, &pluginpb.SongGetRequest{SongId: "song-3"}); !strings.Contains(string(song.Song), "Take On Me") {
+		t.Fatalf("song-3 = %s", song.Song)
+	}
+	if song, _ := b.SongGet(ctx, &pluginpb.SongGetRequest{SongId: "song-9"}); song.Error.GetCode() != "NOT_FOUND" {
+		t.Fatalf("unknown song: error = %v, want NOT_FOUND", song.Error)
+	}
+	if user, _ := b.UserGet(ctx, &pluginpb.UserGetRequest{UserId: "user-1"}); !strings.Contains(string(user.User), "Dev User") {
+		t.Fatalf("user-1 = %s", user.User)
+	}
+	if user, _ := b.UserGet(ctx, &pluginpb.UserGetRequest{UserId: "user-9"}); user.Error.GetCode() != "NOT_FOUND" {
+		t.Fatalf("unknown user: error = %v, want NOT_FOUND", user.Error)
+	}
+}
+
+func TestFixtures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures.json")
+	data := `{"songs": [{"id": "s", "title": "Song"}], "secrets": {"api_key": "k"}}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := LoadFixtures(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := newTestBackend(t, WithFixtures(fixtures))
+	ctx := context.Background()
+
+	if song, _ := b.SongGet(ctx, &pluginpb.SongGetRequest{SongId: "s"}); song.Error != nil {
+		t.Fatalf("fixture song: %v", song.Error)
+	}
+	if secret, _ := b.SecretGet(ctx, &pluginpb.SecretGetRequest{Key: "api_key"}); secret.Value != "k" {
+		t.Fatalf("fixture secret = %q, want k", secret.Value)
+	}
+	_, _ = b.SecretSet(ctx, &pluginpb.SecretSetRequest{Key: "token", Value: "t"})
+	if secret, _ := b.SecretGet(ctx, &pluginpb.SecretGetRequest{Key: "token"}); secret.Value != "t" {
+		t.Fatalf("set secret = %q, want t", secret.Value)
+	}
+	if fixtures.Secrets["token"] != "" {
+		t.Fatal("SecretSet modified the fixtures")
+	}
+
+	if _, err := LoadFixtures(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("loading missing fixtures succeeded")
+	}
+}
+
+func TestLogOutput(t *testing.T) {
+	b, logs := newTestBackend(t)
+	ctx := context.Background()
+
+	_, _ = b.Log(ctx, &pluginpb.LogRequest{Level: "info", Message: "hello", Fields: map[string]string{"b": "2", "a": "1"}})
+	_, _ = b.NotificationSend(ctx, &pluginpb.NotificationSendRequest{Title: "Title", Message: "Body", NotificationType: "info"})
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log output = %q, want 2 lines", logs.String())
+	}
+	if !strings.Contains(lines[0], "INFO") || !strings.HasSuffix(lines[0], "hello a=1 b=2") {
+		t.Errorf("log line = %q, want the level, message and sorted fields", lines[0])
+	}
+	if !strings.Contains(lines[1], "NOTIFY") || !strings.HasSuffix(lines[1], "[info] to all users: Title - Body") {
+		t.Errorf("notification line = %q", lines[1])
+	}
+}
+
+func TestStart(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "storage")
+	b := New(WithStorageDir(dir))
+
+	addr, stop, err := b.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	if strings.HasSuffix(addr, ":0") {
+		t.Fatalf("address = %s, want the port listened on", addr)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Fatalf("storage directory not created: %v", err)
+	}
+}
@@ -0,0 +1,397 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package devcaps
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// encodeKey encodes a storage key into a safe file name.
+func encodeKey(key string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(key))
+}
+
+// decodeKey decodes a file name created by encodeKey.
+func decodeKey(name string) (string, bool) {
+	key, err := base64.RawURLEncoding.DecodeString(name)
+	return string(key), err == nil
+}
+
+// StorageGet implements PluginCapabilitiesServiceServer.StorageGet.
+func (b *Backend) StorageGet(ctx context.Context, req *pluginpb.StorageGetRequest) (*pluginpb.StorageGetResponse, error) {
+	value, err := os.ReadFile(b.storagePath(req.TenantId, req.PluginId, req.Key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &pluginpb.StorageGetResponse{
+			Error: pluginError("NOT_FOUND", "key not found: %s", req.Key),
+		}, nil
+	}
+	if err != nil {
+		return &pluginpb.StorageGetResponse{
+			Error: pluginError("INTERNAL", "failed to read key: %v", err),
+		}, nil
+	}
+	return &pluginpb.StorageGetResponse{Value: value}, nil
+}
+
+// StorageSet implements PluginCapabilitiesServiceServer.StorageSet.
+func (b *Backend) StorageSet(ctx context.Context, req *pluginpb.StorageSetRequest) (*pluginpb.StorageSetResponse, error) {
+	path := b.storagePath(req.TenantId, req.PluginId, req.Key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return &pluginpb.StorageSetResponse{
+			Error: pluginError("INTERNAL", "failed to create storage directory: %v", err),
+		}, nil
+	}
+	if err := os.WriteFile(path, req.Value, 0o644); err != nil {
+		return &pluginpb.StorageSetResponse{
+			Error: pluginError("INTERNAL", "failed to write key: %v", err),
+		}, nil
+	}
+	return &pluginpb.StorageSetResponse{}, nil
+}
+
+// StorageDelete implements PluginCapabilitiesServiceServer.StorageDelete.
+func (b *Backend) StorageDelete(ctx context.Context, req *pluginpb.StorageDeleteRequest) (*pluginpb.StorageDeleteResponse, error) {
+	err := os.Remove(b.storagePath(req.TenantId, req.PluginId, req.Key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return &pluginpb.StorageDeleteResponse{
+			Error: pluginError("INTERNAL", "failed to delete key: %v", err),
+		}, nil
+	}
+	return &pluginpb.StorageDeleteResponse{}, nil
+}
+
+// StorageKeys implements PluginCapabilitiesServiceServer.StorageKeys.
+func (b *Backend) StorageKeys(ctx context.Context, req *pluginpb.StorageKeysRequest) (*pluginpb.StorageKeysResponse, error) {
+	entries, err := os.ReadDir(filepath.Join(b.storageDir, req.TenantId, req.PluginId))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return &pluginpb.StorageKeysResponse{
+			Error: pluginError("INTERNAL", "failed to list keys: %v", err),
+		}, nil
+	}
+
+	keys := []string{}
+	for _, entry := range entries {
+		key, ok := decodeKey(entry.Name())
+		if ok && strings.HasPrefix(key, req.Prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return &pluginpb.StorageKeysResponse{Keys: keys}, nil
+}
+
+// HTTPFetch implements PluginCapabilitiesServiceServer.HTTPFetch.
+func (b *Backend) HTTPFetch(ctx context.Context, req *pluginpb.HTTPFetchRequest) (*pluginpb.HTTPFetchResponse, error) {
+	if req.TimeoutMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	method := req.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, method, req.Url, bytes.NewReader(req.Body))
+	if err != nil {
+		return &pluginpb.HTTPFetchResponse{
+			Error: pluginError("INVALID_ARGUMENT", "invalid request: %v", err),
+		}, nil
+	}
+	for key, value := range req.Headers {
+		httpReq.Header.Set(key, value)
+	}
+
+	httpResp, err := b.httpClient.Do(httpReq)
+	if err != nil {
+		return &pluginpb.HTTPFetchResponse{
+			Error: pluginError("UNAVAILABLE", "request failed: %v", err),
+		}, nil
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return &pluginpb.HTTPFetchResponse{
+			Error: pluginError("UNAVAILABLE", "failed to read response: %v", err),
+		}, nil
+	}
+
+	headers := make(map[string]string, len(httpResp.Header))
+	for key := range httpResp.Header {
+		headers[key] = httpResp.Header.Get(key)
+	}
+	return &pluginpb.HTTPFetchResponse{
+		StatusCode: int32(httpResp.StatusCode),
+		Headers:    headers,
+		Body:       body,
+	}, nil
+}
+
+// QueueGet implements PluginCapabilitiesServiceServer.QueueGet.
+func (b *Backend) QueueGet(ctx context.Context, req *pluginpb.QueueGetRequest) (*pluginpb.QueueGetResponse, error) {
+	b.mu.Lock()
+	queue := append([]stub.QueueItem{}, b.queues[req.TenantId]...)
+	b.mu.Unlock()
+
+	data, err := json.Marshal(queue)
+	if err != nil {
+		return &pluginpb.QueueGetResponse{
+			Error: pluginError("INTERNAL", "failed to marshal queue: %v", err),
+		}, nil
+	}
+	return &pluginpb.QueueGetResponse{QueueData: data}, nil
+}
+
+// QueueAdd implements PluginCapabilitiesServiceServer.QueueAdd.
+// The song is either a song ID from the fixtures or a song object.
+func (b *Backend) QueueAdd(ctx context.Context, req *pluginpb.QueueAddRequest) (*pluginpb.QueueAddResponse, error) {
+	var songData interface{}
+	if err := json.Unmarshal(req.SongData, &songData); err != nil {
+		return &pluginpb.QueueAddResponse{
+			Error: pluginError("INVALID_ARGUMENT", "invalid song data: %v", err),
+		}, nil
+	}
+
+	var song map[string]interface{}
+	switch data := songData.(type) {
+	case string:
+		song = b.findSong(data)
+		if song == nil {
+			return &pluginpb.QueueAddResponse{
+				Error: pluginError("NOT_FOUND", "song not found: %s", data),
+			}, nil
+		}
+	case map[string]interface{}:
+		song = data
+	default:
+		return &pluginpb.QueueAddResponse{
+			Error: pluginError("INVALID_ARGUMENT", "song data must be a song ID or a song object"),
+		}, nil
+	}
+
+	songID, _ := song["id"].(string)
+	item := stub.QueueItem{
+		ID:       b.newID("queue-item"),
+		TenantID: req.TenantId,
+		SongID:   songID,
+		Status:   "queued",
+		Song:     song,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue := b.queues[req.TenantId]
+	position := int(req.Position)
+	if position < 0 || position > len(queue) {
+		position = len(queue)
+	}
+	queue = append(queue[:position], append([]stub.QueueItem{item}, queue[position:]...)...)
+	b.queues[req.TenantId] = renumber(queue)
+	return &pluginpb.QueueAddResponse{}, nil
+}
+
+// QueueRemove implements PluginCapabilitiesServiceServer.QueueRemove.
+func (b *Backend) QueueRemove(ctx context.Context, req *pluginpb.QueueRemoveRequest) (*pluginpb.QueueRemoveResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue := b.queues[req.TenantId]
+	position := int(req.Position)
+	if position < 0 || position >= len(queue) {
+		return &pluginpb.QueueRemoveResponse{
+			Error: pluginError("NOT_FOUND", "no queue item at position %d", position),
+		}, nil
+	}
+	b.queues[req.TenantId] = renumber(append(queue[:position], queue[position+1:]...))
+	return &pluginpb.QueueRemoveResponse{}, nil
+}
+
+// QueueReorder implements PluginCapabilitiesServiceServer.QueueReorder.
+func (b *Backend) QueueReorder(ctx context.Context, req *pluginpb.QueueReorderRequest) (*pluginpb.QueueReorderResponse, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	queue := b.queues[req.TenantId]
+	from, to := int(req.FromPosition), int(req.ToPosition)
+	if from < 0 || from >= len(queue) || to < 0 || to >= len(queue) {
+		return &pluginpb.QueueReorderResponse{
+			Error: pluginError("INVALID_ARGUMENT", "positions %d and %d must be within the queue", from, to),
+		}, nil
+	}
+
+	item := queue[from]
+	queue = append(queue[:from], queue[from+1:]...)
+	queue = append(queue[:to], append([]stub.QueueItem{item}, queue[to:]...)...)
+	b.queues[req.TenantId] = renumber(queue)
+	return &pluginpb.QueueReorderResponse{}, nil
+}
+
+// renumber sets the position of every queue item to its index.
+func renumber(queue []stub.QueueItem) []stub.QueueItem {
+	for i := range queue {
+		queue[i].Position = i
+	}
+	return queue
+}
+
+// NotificationSend implements PluginCapabilitiesServiceServer.NotificationSend.
+// Notifications are written to the log output.
+func (b *Backend) NotificationSend(ctx context.Context, req *pluginpb.NotificationSendRequest) (*pluginpb.NotificationSendResponse, error) {
+	id := b.newID("notification")
+	recipient := req.UserId
+	if recipient == "" {
+		recipient = "all users"
+	}
+	b.writeLine("NOTIFY", fmt.Sprintf("[%s] to %s: %s - %s", req.NotificationType, recipient, req.Title, req.Message), nil)
+	return &pluginpb.NotificationSendResponse{NotificationId: id}, nil
+}
+
+// SecretGet implements PluginCapabilitiesServiceServer.SecretGet.
+func (b *Backend) SecretGet(ctx context.Context, req *pluginpb.SecretGetRequest) (*pluginpb.SecretGetResponse, error) {
+	b.mu.Lock()
+	value, exists := b.secrets[req.Key]
+	b.mu.Unlock()
+
+	if !exists {
+		return &pluginpb.SecretGetResponse{
+			Error: pluginError("NOT_FOUND", "secret not found: %s", req.Key),
+		}, nil
+	}
+	return &pluginpb.SecretGetResponse{Value: value}, nil
+}
+
+// SecretSet implements PluginCapabilitiesServiceServer.SecretSet.
+func (b *Backend) SecretSet(ctx context.Context, req *pluginpb.SecretSetRequest) (*pluginpb.SecretSetResponse, error) {
+	b.mu.Lock()
+	b.secrets[req.Key] = req.Value
+	b.mu.Unlock()
+	return &pluginpb.SecretSetResponse{}, nil
+}
+
+// SongSearch implements PluginCapabilitiesServiceServer.SongSearch.
+// Songs match when their title, artist or channel contains the query.
+func (b *Backend) SongSearch(ctx context.Context, req *pluginpb.SongSearchRequest) (*pluginpb.SongSearchResponse, error) {
+	query := strings.ToLower(req.Query)
+	songs := []map[string]interface{}{}
+	for _, song := range b.fixtures.Songs {
+		if req.Limit > 0 && len(songs) >= int(req.Limit) {
+			break
+		}
+		for _, field := range []string{"title", "artist", "channel"} {
+			value, _ := song[field].(string)
+			if strings.Contains(strings.ToLower(value), query) {
+				songs = append(songs, song)
+				break
+			}
+		}
+	}
+
+	data, err := json.Marshal(songs)
+	if err != nil {
+		return &pluginpb.SongSearchResponse{
+			Error: pluginError("INTERNAL", "failed to marshal songs: %v", err),
+		}, nil
+	}
+	return &pluginpb.SongSearchResponse{Songs: data}, nil
+}
+
+// SongGet implements PluginCapabilitiesServiceServer.SongGet.
+func (b *Backend) SongGet(ctx context.Context, req *pluginpb.SongGetRequest) (*pluginpb.SongGetResponse, error) {
+	song := b.findSong(req.SongId)
+	if song == nil {
+		return &pluginpb.SongGetResponse{
+			Error: pluginError("NOT_FOUND", "song not found: %s", req.SongId),
+		}, nil
+	}
+
+	data, err := json.Marshal(song)
+	if err != nil {
+		return &pluginpb.SongGetResponse{
+			Error: pluginError("INTERNAL", "failed to marshal song: %v", err),
+		}, nil
+	}
+	return &pluginpb.SongGetResponse{Song: data}, nil
+}
+
+// findSong returns the fixture song with the given ID, or nil.
+func (b *Backend) findSong(id string) map[string]interface{} {
+	for _, song := range b.fixtures.Songs {
+		if song["id"] == id {
+			return song
+		}
+	}
+	return nil
+}
+
+// UserGet implements PluginCapabilitiesServiceServer.UserGet.
+func (b *Backend) UserGet(ctx context.Context, req *pluginpb.UserGetRequest) (*pluginpb.UserGetResponse, error) {
+	for _, user := range b.fixtures.Users {
+		if user["id"] != req.UserId {
+			continue
+		}
+		data, err := json.Marshal(user)
+		if err != nil {
+			return &pluginpb.UserGetResponse{
+				Error: pluginError("INTERNAL", "failed to marshal user: %v", err),
+			}, nil
+		}
+		return &pluginpb.UserGetResponse{User: data}, nil
+	}
+	return &pluginpb.UserGetResponse{
+		Error: pluginError("NOT_FOUND", "user not found: %s", req.UserId),
+	}, nil
+}
+
+// Log implements PluginCapabilitiesServiceServer.Log.
+// Logs are written to the log output.
+func (b *Backend) Log(ctx context.Context, req *pluginpb.LogRequest) (*pluginpb.LogResponse, error) {
+	b.writeLine(strings.ToUpper(req.Level), req.Message, req.Fields)
+	return &pluginpb.LogResponse{}, nil
+}
+
+// writeLine writes a log line with sorted fields to the log output.
+func (b *Backend) writeLine(level, message string, fields map[string]string) {
+	var line strings.Builder
+	fmt.Fprintf(&line, "%s %-6s %s", time.Now().Format("15:04:05.000"), level, message)
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&line, " %s=%s", key, fields[key])
+	}
+	line.WriteByte('\n')
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, _ = io.WriteString(b.logOutput, line.String())
+}
@@ -0,0 +1,384 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/google/uuid"
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc/metadata"
+)
+
+// DefaultDevAddr is the address the dev mode HTTP interface listens on by default.
+const DefaultDevAddr = "127.0.0.1:8765"
+
+// EnvDev set to "true" runs the plugin in dev mode, like the --dev flag.
+const EnvDev = "WABISABY_DEV"
+
+// Tenant and plugin IDs used for every call in dev mode.
+var (
+	DevTenantID = uuid.MustParse("00000000-0000-0000-0000-00000000de01")
+	DevPluginID = uuid.MustParse("00000000-0000-0000-0000-00000000de02")
+)
+
+// devOptions are the dev mode settings given on the command line.
+type devOptions struct {
+	addr         string
+	configFile   string
+	fixturesFile string
+	storageDir   string
+}
+
+// parseDevArgs parses the dev mode flags in args, ignoring other arguments.
+// Returns whether dev mode was requested with --dev.
+func parseDevArgs(args []string) (devOptions, bool, error) {
+	var devArgs []string
+	for i := 0; i < len(args); i++ {
+		name := strings.TrimLeft(args[i], "-")
+		isDevFlag := name == "dev" || strings.HasPrefix(name, "dev=") || strings.HasPrefix(name, "dev-")
+		if !strings.HasPrefix(args[i], "-") || !isDevFlag {
+			continue
+		}
+		devArgs = append(devArgs, args[i])
+		// Value flags may take their value from the next argument
+		if strings.HasPrefix(name, "dev-") && !strings.Contains(name, "=") && i+1 < len(args) {
+			devArgs = append(devArgs, args[i+1])
+			i++
+		}
+	}
+
+	var opts devOptions
+	flags := flag.NewFlagSet("dev", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	dev := flags.Bool("dev", false, "run the plugin without the host")
+	flags.StringVar(&opts.addr, "dev-addr", DefaultDevAddr, "address of the HTTP interface")
+	flags.StringVar(&opts.configFile, "dev-config", "", "JSON file with the plugin config")
+	flags.StringVar(&opts.fixturesFile, "dev-fixtures", "", "JSON file with songs, users and secrets")
+	flags.StringVar(&opts.storageDir, "dev-storage", devcaps.DefaultStorageDir, "directory storage is kept in")
+	if err := flags.Parse(devArgs); err != nil {
+		return devOptions{}, false, fmt.Errorf("invalid dev mode flags: %w", err)
+	}
+	return opts, *dev, nil
+}
+
+// serveDev runs plugin against a fake capabilities backend and serves its
+// commands over HTTP until the process is interrupted.
+func serveDev(plugin Plugin, dev devOptions, config *serveConfig) error {
+	fixtures := devcaps.DefaultFixtures()
+	if dev.fixturesFile != "" {
+		var err error
+		if fixtures, err = devcaps.LoadFixtures(dev.fixturesFile); err != nil {
+			return err
+		}
+	}
+
+	var pluginConfig []byte
+	if dev.configFile != "" {
+		var err error
+		if pluginConfig, err = os.ReadFile(dev.configFile); err != nil {
+			return fmt.Errorf("failed to read config: %w", err)
+		}
+		if !json.Valid(pluginConfig) {
+			return fmt.Errorf("config file %s is not valid JSON", dev.configFile)
+		}
+	}
+
+	backend := devcaps.New(
+		devcaps.WithStorageDir(dev.storageDir),
+		devcaps.WithFixtures(fixtures),
+	)
+	capabilitiesAddr, stopBackend, err := backend.Start("127.0.0.1:0")
+	if err != nil {
+		return fmt.Errorf("failed to start capabilities backend: %w", err)
+	}
+	defer stopBackend()
+
+	// The fake backend listens locally without TLS
+	config.capabilitiesAddr = capabilitiesAddr
+	config.security = capabilitiesSecurity{token: config.security.token}
+	server, err := newServer(plugin, config)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
+	}
+	defer server.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	initResp, _ := server.InitializePlugin(ctx, &pluginpb.InitializePluginRequest{
+		TenantId: DevTenantID.String(),
+		PluginId: DevPluginID.String(),
+		Config:   pluginConfig,
+	})
+	if initResp.Error != nil {
+		return fmt.Errorf("failed to initialize plugin: %s: %s", initResp.Error.Code, initResp.Error.Message)
+	}
+	enableResp, _ := server.EnablePlugin(ctx, &pluginpb.EnablePluginRequest{
+		TenantId: DevTenantID.String(),
+		PluginId: DevPluginID.String(),
+		Config:   pluginConfig,
+	})
+	if enableResp.Error != nil {
+		return fmt.Errorf("failed to enable plugin: %s: %s", enableResp.Error.Code, enableResp.Error.Message)
+	}
+
+	// Print progress updates as the host would receive them
+	updates, unsubscribe := server.progress.subscribe("")
+	defer unsubscribe()
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case update := <-updates:
+				fmt.Fprintf(os.Stderr, "progress %s %3.0f%% %s request_id=%s\n",
+					update.command, update.percent, update.message, update.requestID)
+			}
+		}
+	}()
+
+	lis, err := net.Listen("tcp", dev.addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen: %w", err)
+	}
+	httpServer := &http.Server{Handler: server.devHandler()}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(lis)
+	}()
+
+	base := "http://" + lis.Addr().String()
+	fmt.Fprintf(os.Stderr, "WabiSaby plugin dev mode\n"+
+		"  GET  %[1]s/commands         list commands\n"+
+		"  POST %[1]s/commands/{name}  call a command with a JSON array of arguments\n"+
+		"  GET  %[1]s/health           health check\n"+
+		"  GET  %[1]s/metrics          metrics\n"+
+		"  storage: %[2]s, tenant: %[3]s\n",
+		base, dev.storageDir, DevTenantID)
+
+	select {
+	case <-ctx.Done():
+	case err = <-serveErr:
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), server.config.drainTimeout)
+	defer cancel()
+	_ = httpServer.Shutdown(shutdownCtx)
+	shutdownResp, _ := server.ShutdownPlugin(shutdownCtx, &pluginpb.ShutdownPluginRequest{
+		TenantId: DevTenantID.String(),
+		PluginId: DevPluginID.String(),
+	})
+
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("dev mode HTTP interface failed: %w", err)
+	}
+	if shutdownResp.Error != nil {
+		return fmt.Errorf("failed to shut down plugin: %s: %s", shutdownResp.Error.Code, shutdownResp.Error.Message)
+	}
+	return nil
+}
+
+// devResponse is the JSON body of a command call in dev mode.
+type devResponse struct {
+	Data            json.RawMessage `json:"data,omitempty"`
+	Error           *devError       `json:"error,omitempty"`
+	ExecutionTimeMs int64           `json:"execution_time_ms"`
+}
+
+// devError is the error of a command call in dev mode.
+type devError struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Retryable bool              `json:"retryable,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// devHandler returns the dev mode HTTP interface:
+//
+//	GET  /commands         the metadata of all commands
+//	POST /commands/{name}  calls a command; the body is a JSON array of
+//	                       arguments, a single JSON argument, or empty
+//	GET  /health           the health check status
+//	GET  /metrics          the metrics snapshot
+//
+// Calls accept a timeout_ms query parameter, and request metadata such as
+// X-Request-Id as headers.
+func (s *Server) devHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /commands", func(w http.ResponseWriter, r *http.Request) {
+		s.devCall(w, r, CommandsCommand, nil)
+	})
+	mux.HandleFunc("POST /commands/{name}", func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			writeDevError(w, InvalidArgument("failed to read body: %v", err).pluginError())
+			return
+		}
+		args, err := devArgs(body)
+		if err != nil {
+			writeDevError(w, InvalidArgument("%v", err).pluginError())
+			return
+		}
+		s.devCall(w, r, r.PathValue("name"), args)
+	})
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		resp, _ := s.HealthCheck(r.Context(), &pluginpb.HealthCheckRequest{})
+		status := http.StatusOK
+		if resp.Status != pluginpb.HealthCheckResponse_SERVING {
+			status = http.StatusServiceUnavailable
+		}
+		writeDevJSON(w, status, map[string]string{"status": resp.Status.String()})
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		writeDevJSON(w, http.StatusOK, s.Metrics())
+	})
+	return mux
+}
+
+// devArgs splits a request body into the JSON arguments of a command.
+func devArgs(body []byte) ([][]byte, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, nil
+	}
+	if body[0] != '[' {
+		if !json.Valid(body) {
+			return nil, fmt.Errorf("body is not valid JSON")
+		}
+		return [][]byte{body}, nil
+	}
+
+	var raw []json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("invalid arguments: %v", err)
+	}
+	args := make([][]byte, len(raw))
+	for i, arg := range raw {
+		args[i] = arg
+	}
+	return args, nil
+}
+
+// devCall executes a command for the dev tenant and writes its result.
+func (s *Server) devCall(w http.ResponseWriter, r *http.Request, command string, args [][]byte) {
+	var timeoutMs int64
+	if value := r.URL.Query().Get("timeout_ms"); value != "" {
+		var err error
+		if timeoutMs, err = strconv.ParseInt(value, 10, 64); err != nil {
+			writeDevError(w, InvalidArgument("invalid timeout_ms: %v", err).pluginError())
+			return
+		}
+	}
+
+	// Pass request metadata headers on as the host would
+	md := metadata.MD{}
+	for _, key := range []string{MetadataRequestID, MetadataUserID, MetadataUserRoles, MetadataLocale, MetadataTraceParent, MetadataTraceState} {
+		if value := r.Header.Get(key); value != "" {
+			md.Set(key, value)
+		}
+	}
+	ctx := metadata.NewIncomingContext(r.Context(), md)
+
+	resp, _ := s.ExecuteCommand(ctx, &pluginpb.ExecuteCommandRequest{
+		TenantId:  DevTenantID.String(),
+		PluginId:  DevPluginID.String(),
+		Command:   command,
+		Args:      args,
+		TimeoutMs: timeoutMs,
+	})
+	if pluginErr := resp.GetError(); pluginErr != nil {
+		writeDevJSON(w, devHTTPStatus(pluginErr.Code), devResponse{
+			Error:           newDevError(pluginErr),
+			ExecutionTimeMs: resp.ExecutionTimeMs,
+		})
+		return
+	}
+	writeDevJSON(w, http.StatusOK, devResponse{
+		Data:            resp.GetData(),
+		ExecutionTimeMs: resp.ExecutionTimeMs,
+	})
+}
+
+// newDevError converts a plugin error for a dev mode response.
+func newDevError(pluginErr *pluginpb.PluginError) *devError {
+	retryable, details := errorDetails(pluginErr)
+	return &devError{
+		Code:      pluginErr.Code,
+		Message:   pluginErr.Message,
+		Retryable: retryable,
+		Details:   details,
+	}
+}
+
+// writeDevError writes an error response for a call that was not executed.
+func writeDevError(w http.ResponseWriter, pluginErr *pluginpb.PluginError) {
+	writeDevJSON(w, devHTTPStatus(pluginErr.Code), devResponse{Error: newDevError(pluginErr)})
+}
+
+// writeDevJSON writes v as a JSON response.
+func writeDevJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
+
+// devHTTPStatus returns the HTTP status reporting an error code.
+func devHTTPStatus(code string) int {
+	switch ErrorCode(code) {
+	case CodeNotFound:
+		return http.StatusNotFound
+	case CodeInvalidArgument, CodeSerializationError:
+		return http.StatusBadRequest
+	case CodePermissionDenied:
+		return http.StatusForbidden
+	case CodeRateLimited, CodeResourceExhausted:
+		return http.StatusTooManyRequests
+	case CodeDeadlineExceeded:
+		return http.StatusGatewayTimeout
+	case CodeUnavailable:
+		return http.StatusServiceUnavailable
+	case CodeNotSupported:
+		return http.StatusNotImplemented
+	}
+	return http.StatusInternalServerError
+}
+
+// devFromEnv checks if dev mode was requested with EnvDev.
+// Returns an error if EnvDev is set but not a boolean.
+func devFromEnv() (bool, error) {
+	value := os.Getenv(EnvDev)
+	if value == "" {
+		return false, nil
+	}
+	dev, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: want true or false", EnvDev, value)
+	}
+	return dev, nil
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+)
+
+// devCallResult is a command call made through the dev mode HTTP interface.
+type devCallResult struct {
+	status int
+	body   devResponse
+}
+
+// devRequest sends a request to the dev mode HTTP interface of server.
+func devRequest(t *testing.T, server *Server, method, path, body string, header http.Header) devCallResult {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	server.devHandler().ServeHTTP(rec, req)
+
+	var resp devResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("%s %s: decode %q: %v", method, path, rec.Body.String(), err)
+	}
+	return devCallResult{status: rec.Code, body: resp}
+}
+
+// devPlugin has an "add" command summing a and b, a "store" command
+// keeping a value in storage, and a "request" command returning its request ID.
+func devPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("add", func(ctx *Context, args *struct {
+		A int `json:"a"`
+		B int `json:"b"`
+	}) (interface{}, error) {
+		return args.A + args.B, nil
+	}))
+	must(t, plugin.RegisterCommand("store", func(ctx *Context, args *struct {
+		Value string `json:"value"`
+	}) (interface{}, error) {
+		if err := ctx.Storage.Set(ctx, "value", args.Value); err != nil {
+			return nil, err
+		}
+		return ctx.Storage.Get(ctx, "value")
+	}))
+	must(t, plugin.RegisterCommand("request", func(ctx *Context) (interface{}, error) {
+		return ctx.Request.RequestID, nil
+	}))
+	return plugin
+}
+
+func TestDevHandlerCallsCommands(t *testing.T) {
+	server := newTestServer(t, devPlugin(t))
+
+	sum := devRequest(t, server, "POST", "/commands/add", `[{"a": 2, "b": 3}]`, nil)
+	if sum.status != http.StatusOK || string(sum.body.Data) != "5" {
+		t.Fatalf("add = %d %s, want 200 5", sum.status, sum.body.Data)
+	}
+
+	stored := devRequest(t, server, "POST", "/commands/store", `{"value": "kept"}`, nil)
+	if stored.status != http.StatusOK || string(stored.body.Data) != `"kept"` {
+		t.Fatalf("store = %d %s %+v, want 200 \"kept\"", stored.status, stored.body.Data, stored.body.Error)
+	}
+
+	header := http.Header{}
+	header.Set(MetadataRequestID, "dev-req")
+	request := devRequest(t, server, "POST", "/commands/request", "", header)
+	if string(request.body.Data) != `"dev-req"` {
+		t.Fatalf("request ID = %s, want the X-Request-Id header", request.body.Data)
+	}
+
+	commands := devRequest(t, server, "GET", "/commands", "", nil)
+	var metadata []CommandMetadata
+	must(t, json.Unmarshal(commands.body.Data, &metadata))
+	if len(metadata) != 3 || metadata[0].Name != "add" {
+		t.Fatalf("commands = %+v, want add, request and store", metadata)
+	}
+}
+
+func TestDevHandlerErrors(t *testing.T) {
+	server := newTestServer(t, devPlugin(t))
+
+	tests := []struct {
+		name       string
+		path, body string
+		wantStatus int
+		wantCode   ErrorCode
+	}{
+		{"unknown command", "/commands/missing", "", http.StatusNotFound, CodeNotFound},
+		{"invalid JSON", "/commands/add", "[1,", http.StatusBadRequest, CodeInvalidArgument},
+		{"invalid timeout", "/commands/add?timeout_ms=soon", `{"a": 1}`, http.StatusBadRequest, CodeInvalidArgument},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := devRequest(t, server, "POST", tt.path, tt.body, nil)
+			if got.status != tt.wantStatus || got.body.Error == nil || got.body.Error.Code != string(tt.wantCode) {
+				t.Fatalf("status %d, error %+v, want %d %s", got.status, got.body.Error, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestDevHandlerHealthAndMetrics(t *testing.T) {
+	server := newTestServer(t, devPlugin(t))
+	devRequest(t, server, "POST", "/commands/add", `{"a": 1, "b": 2}`, nil)
+
+	rec := httptest.NewRecorder()
+	server.devHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	var snapshot MetricsSnapshot
+	must(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": "add"}); got != 1 {
+		t.Fatalf("add calls in /metrics = %d, want 1", got)
+	}
+
+	rec = httptest.NewRecorder()
+	server.devHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "NOT_SERVING") {
+		t.Fatalf("/health before initialization = %d %s, want 503 NOT_SERVING", rec.Code, rec.Body.String())
+	}
+}
+
+func TestDevArgs(t *testing.T) {
+	tests := []struct {
+		body    string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"  ", nil, false},
+		{`{"a": 1}`, []string{`{"a": 1}`}, false},
+		{`"text"`, []string{`"text"`}, false},
+		{`[1, "two", {"three": 3}]`, []string{`1`, `"two"`, `{"three": 3}`}, false},
+		{`{bad`, nil, true},
+		{`[1,`, nil, true},
+	}
+	for _, tt := range tests {
+		args, err := devArgs([]byte(tt.body))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("devArgs(%q) error = %v, want error %v", tt.body, err, tt.wantErr)
+			continue
+		}
+		var got []string
+		for _, arg := range args {
+			got = append(got, string(arg))
+		}
+		if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("devArgs(%q) = %q, want %q", tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestParseDevArgs(t *testing.T) {
+	opts, dev, err := parseDevArgs([]string{"--verbose", "--dev", "--dev-addr", ":9000", "-dev-storage=/tmp/s", "positional"})
+	must(t, err)
+	if !dev || opts.addr != ":9000" || opts.storageDir != "/tmp/s" {
+		t.Fatalf("parsed %+v, dev %v, want dev mode on :9000 with storage /tmp/s", opts, dev)
+	}
+
+	opts, dev, err = parseDevArgs([]string{"--verbose"})
+	must(t, err)
+	if dev || opts.addr != DefaultDevAddr || opts.storageDir != devcaps.DefaultStorageDir {
+		t.Fatalf("parsed %+v, dev %v, want the defaults without dev mode", opts, dev)
+	}
+
+	if _, _, err := parseDevArgs([]string{"--dev-unknown=1"}); err == nil {
+		t.Fatal("unknown dev flag accepted")
+	}
+}
+
+func TestDevFromEnv(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{"", false, false},
+		{"true", true, false},
+		{"1", true, false},
+		{"false", false, false},
+		{"yes", false, true},
+	}
+	for _, tt := range tests {
+		t.Setenv(EnvDev, tt.value)
+		got, err := devFromEnv()
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("devFromEnv with %s=%q = %v, %v, want %v, error %v", EnvDev, tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// The PluginError of protos v0.0.1 has no retryable flag or details, so the
+// server also reports them for failed commands in gRPC trailers.
+const (
+	// ErrorRetryableTrailer is the gRPC trailer reporting whether the host
+	// may retry a failed command, as "true" or "false".
+	ErrorRetryableTrailer = "x-wabisaby-error-retryable"
+
+	// ErrorDetailsTrailer is the gRPC trailer reporting the details of the
+	// error of a failed command, as a JSON object. It is omitted when the
+	// error has no details.
+	ErrorDetailsTrailer = "x-wabisaby-error-details"
+)
+
+// ErrorCode is a canonical error code reported to the host.
+type ErrorCode string
+
+// Canonical error codes for handler failures.
+const (
+	CodeNotFound           ErrorCode = "NOT_FOUND"
+	CodeDeadlineExceeded   ErrorCode = "DEADLINE_EXCEEDED"
+	CodeCancelled          ErrorCode = "CANCELLED"
+	CodeInvalidArgument    ErrorCode = "INVALID_ARGUMENT"
+	CodeFailedPrecondition ErrorCode = "FAILED_PRECONDITION"
+	CodePermissionDenied   ErrorCode = "PERMISSION_DENIED"
+	CodeRateLimited        ErrorCode = "RATE_LIMITED"
+	CodeResourceExhausted  ErrorCode = "RESOURCE_EXHAUSTED"
+	CodeUnavailable        ErrorCode = "UNAVAILABLE"
+	CodeInternal           ErrorCode = "INTERNAL"
+)
+
+// Error codes reported by the server itself.
+const (
+	CodeNotSupported        ErrorCode = "NOT_SUPPORTED"
+	CodeExecutionError      ErrorCode = "EXECUTION_ERROR"
+	CodeSerializationError  ErrorCode = "SERIALIZATION_ERROR"
+	CodeInitializationError ErrorCode = "INITIALIZATION_ERROR"
+	CodeShutdownError       ErrorCode = "SHUTDOWN_ERROR"
+	CodeEnableError         ErrorCode = "ENABLE_ERROR"
+	CodeDisableError        ErrorCode = "DISABLE_ERROR"
+	CodeEventError          ErrorCode = "EVENT_ERROR"
+	CodePanic               ErrorCode = "PANIC"
+)
+
+// Error is a structured plugin error.
+// Handlers return it to report a specific code to the host instead of a
+// generic execution error.
+type Error struct {
+	Code      ErrorCode         // Canonical error code
+	Message   string            // Human-readable message
+	Retryable bool              // Whether the host may retry the call
+	Details   map[string]string // Optional structured details
+	Cause     error             // Optional underlying error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+	}
+	return e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// WithDetail adds a detail to the error and returns it.
+func (e *Error) WithDetail(key, value string) *Error {
+	if e.Details == nil {
+		e.Details = make(map[string]string)
+	}
+	e.Details[key] = value
+	return e
+}
+
+// WithCause sets the underlying error and returns the error.
+func (e *Error) WithCause(err error) *Error {
+	e.Cause = err
+	return e
+}
+
+// WithRetryable sets whether the host may retry the call and returns the error.
+func (e *Error) WithRetryable(retryable bool) *Error {
+	e.Retryable = retryable
+	return e
+}
+
+// NewError creates an error with the given code and formatted message.
+func NewError(code ErrorCode, format string, args ...interface{}) *Error {
+	return &Error{
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// NotFound creates a NOT_FOUND error.
+func NotFound(format string, args ...interface{}) *Error {
+	return NewError(CodeNotFound, format, args...)
+}
+
+// DeadlineExceeded creates a DEADLINE_EXCEEDED error.
+func DeadlineExceeded(format string, args ...interface{}) *Error {
+	return NewError(CodeDeadlineExceeded, format, args...)
+}
+
+// Cancelled creates a CANCELLED error.
+func Cancelled(format string, args ...interface{}) *Error {
+	return NewError(CodeCancelled, format, args...)
+}
+
+// InvalidArgument creates an INVALID_ARGUMENT error.
+func InvalidArgument(format string, args ...interface{}) *Error {
+	return NewError(CodeInvalidArgument, format, args...)
+}
+
+// FailedPrecondition creates a FAILED_PRECONDITION error.
+func FailedPrecondition(format string, args ...interface{}) *Error {
+	return NewError(CodeFailedPrecondition, format, args...)
+}
+
+// PermissionDenied creates a PERMISSION_DENIED error.
+func PermissionDenied(format string, args ...interface{}) *Error {
+	return NewError(CodePermissionDenied, format, args...)
+}
+
+// RateLimited creates a retryable RATE_LIMITED error.
+func RateLimited(format string, args ...interface{}) *Error {
+	return NewError(CodeRateLimited, format, args...).WithRetryable(true)
+}
+
+// ResourceExhausted creates a retryable RESOURCE_EXHAUSTED error.
+func ResourceExhausted(format string, args ...interface{}) *Error {
+	return NewError(CodeResourceExhausted, format, args...).WithRetryable(true)
+}
+
+// Unavailable creates a retryable UNAVAILABLE error.
+func Unavailable(format string, args ...interface{}) *Error {
+	return NewError(CodeUnavailable, format, args...).WithRetryable(true)
+}
+
+// Internal creates an INTERNAL error.
+func Internal(format string, args ...interface{}) *Error {
+	return NewError(CodeInternal, format, args...)
+}
+
+// toPluginError converts an error into a PluginError.
+// Errors wrapping an *Error keep their code, retryable flag and details;
+// any other error is reported with the fallback code. The message is the
+// full text of err, with the context added by wrapping it.
+func toPluginError(err error, fallback ErrorCode) *pluginpb.PluginError {
+	var sdkErr *Error
+	if errors.As(err, &sdkErr) {
+		pluginErr := sdkErr.pluginError()
+		pluginErr.Message = err.Error()
+		return pluginErr
+	}
+	return &pluginpb.PluginError{
+		Code:    string(fallback),
+		Message: err.Error(),
+	}
+}
+
+// pluginError converts the error into a PluginError.
+func (e *Error) pluginError() *pluginpb.PluginError {
+	pluginErr := &pluginpb.PluginError{
+		Code:    string(e.Code),
+		Message: e.Error(),
+	}
+	retryable, details := errorReport(e)
+	setErrorDetails(pluginErr, retryable, details)
+	return pluginErr
+}
+
+// errorReport returns the retryable flag and details reported for err.
+// They are those of the *Error err wraps, with its cause as the "cause"
+// detail; any other error is not retryable and has no details.
+func errorReport(err error) (bool, map[string]string) {
+	var sdkErr *Error
+	if !errors.As(err, &sdkErr) {
+		return false, nil
+	}
+	if sdkErr.Cause == nil {
+		return sdkErr.Retryable, sdkErr.Details
+	}
+
+	details := make(map[string]string, len(sdkErr.Details)+1)
+	for key, value := range sdkErr.Details {
+		details[key] = value
+	}
+	details["cause"] = sdkErr.Cause.Error()
+	return sdkErr.Retryable, details
+}
+
+// commandError converts the error of a command into a PluginError. The
+// retryable flag and details are also reported in the gRPC trailers of the
+// call, see ErrorRetryableTrailer.
+func commandError(ctx context.Context, err error, fallback ErrorCode) *pluginpb.PluginError {
+	retryable, details := errorReport(err)
+	md := metadata.Pairs(ErrorRetryableTrailer, strconv.FormatBool(retryable))
+	if len(details) > 0 {
+		if data, marshalErr := json.Marshal(details); marshalErr == nil {
+			md.Append(ErrorDetailsTrailer, string(data))
+		}
+	}
+	_ = grpc.SetTrailer(ctx, md)
+	return toPluginError(err, fallback)
+}
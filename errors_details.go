@@ -0,0 +1,29 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// setErrorDetails sets the retryable flag and details of a PluginError.
+func setErrorDetails(pluginErr *pluginpb.PluginError, retryable bool, details map[string]string) {
+	pluginErr.Retryable = retryable
+	pluginErr.Details = details
+}
+
+// errorDetails returns the retryable flag and details of a PluginError.
+func errorDetails(pluginErr *pluginpb.PluginError) (bool, map[string]string) {
+	return pluginErr.Retryable, pluginErr.Details
+}
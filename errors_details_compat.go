@@ -0,0 +1,32 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build !wabisaby_protos_next
+
+package sdk
+
+import (
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// The PluginError of the released protos has no retryable flag or details.
+// Failed commands report them in gRPC trailers instead, see commandError.
+// Build with the wabisaby_protos_next tag against protos that have them to
+// also report them in the PluginError.
+
+// setErrorDetails drops the retryable flag and details of a PluginError.
+func setErrorDetails(pluginErr *pluginpb.PluginError, retryable bool, details map[string]string) {
+}
+
+// errorDetails returns no retryable flag or details.
+func errorDetails(pluginErr *pluginpb.PluginError) (bool, map[string]string) {
+	return false, nil
+}
@@ -0,0 +1,36 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPluginErrorDetails(t *testing.T) {
+	err := Unavailable("song service unavailable").
+		WithDetail("service", "songs").
+		WithCause(errors.New("connection refused"))
+
+	pluginErr := toPluginError(err, CodeExecutionError)
+	if !pluginErr.Retryable {
+		t.Fatal("UNAVAILABLE error not reported as retryable")
+	}
+	if pluginErr.Details["service"] != "songs" || pluginErr.Details["cause"] != "connection refused" {
+		t.Fatalf("details = %v", pluginErr.Details)
+	}
+	if _, leaked := err.Details["cause"]; leaked {
+		t.Fatal("cause added to the details of the error itself")
+	}
+}
@@ -0,0 +1,128 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// trailerStream records the gRPC trailers set on a call.
+type trailerStream struct {
+	grpc.ServerTransportStream
+	trailer metadata.MD
+}
+
+func (s *trailerStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+	return nil
+}
+
+func TestToPluginError(t *testing.T) {
+	cause := errors.New("connection refused")
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    ErrorCode
+		wantMessage string
+	}{
+		{"sdk error", NotFound("song %s", "s1"), CodeNotFound, "song s1"},
+		{"cause in message", Unavailable("song service unavailable").WithCause(cause), CodeUnavailable, "song service unavailable: connection refused"},
+		{"wrapped sdk error", fmt.Errorf("loading song: %w", RateLimited("slow down")), CodeRateLimited, "loading song: slow down"},
+		{"plain error", cause, CodeExecutionError, "connection refused"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pluginErr := toPluginError(tt.err, CodeExecutionError)
+			if pluginErr.Code != string(tt.wantCode) || pluginErr.Message != tt.wantMessage {
+				t.Fatalf("toPluginError = %s %q, want %s %q", pluginErr.Code, pluginErr.Message, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestErrorMessage(t *testing.T) {
+	err := Internal("failed to save").WithCause(errors.New("disk full"))
+	if got := err.Error(); got != "failed to save: disk full" {
+		t.Fatalf("Error() = %q", got)
+	}
+	if !errors.Is(err, err.Cause) {
+		t.Fatal("error does not unwrap to its cause")
+	}
+}
+
+func TestCommandErrorCodes(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("song", func(ctx *Context) (interface{}, error) {
+		return nil, NotFound("song %s", "s1").WithDetail("song_id", "s1")
+	}))
+	must(t, plugin.RegisterCommand("fail", func(ctx *Context) (interface{}, error) {
+		return nil, errors.New("boom")
+	}))
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	if err := target.execute(t, server, context.Background(), nil, "song"); err.GetCode() != string(CodeNotFound) || err.Message != "song s1" {
+		t.Fatalf("song failed with %+v", err)
+	}
+	if err := target.execute(t, server, context.Background(), nil, "fail"); err.GetCode() != string(CodeExecutionError) || err.Message != "boom" {
+		t.Fatalf("fail failed with %+v", err)
+	}
+}
+
+func TestCommandErrorTrailers(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("song", func(ctx *Context) (interface{}, error) {
+		err := Unavailable("song service unavailable").
+			WithDetail("service", "songs").
+			WithCause(errors.New("connection refused"))
+		return nil, fmt.Errorf("loading song: %w", err)
+	}))
+	must(t, plugin.RegisterCommand("fail", func(ctx *Context) (interface{}, error) {
+		return nil, errors.New("boom")
+	}))
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	stream := &trailerStream{}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	pluginErr := target.execute(t, server, ctx, nil, "song")
+	if want := "loading song: song service unavailable: connection refused"; pluginErr.GetMessage() != want {
+		t.Fatalf("message = %q, want %q", pluginErr.GetMessage(), want)
+	}
+	if got := stream.trailer.Get(ErrorRetryableTrailer); len(got) != 1 || got[0] != "true" {
+		t.Fatalf("%s trailer = %v, want true", ErrorRetryableTrailer, got)
+	}
+	var details map[string]string
+	if got := stream.trailer.Get(ErrorDetailsTrailer); len(got) != 1 || json.Unmarshal([]byte(got[0]), &details) != nil {
+		t.Fatalf("%s trailer = %v, want a JSON object", ErrorDetailsTrailer, got)
+	}
+	if details["service"] != "songs" || details["cause"] != "connection refused" {
+		t.Fatalf("details = %v", details)
+	}
+
+	stream = &trailerStream{}
+	ctx = grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	target.execute(t, server, ctx, nil, "fail")
+	if got := stream.trailer.Get(ErrorRetryableTrailer); len(got) != 1 || got[0] != "false" {
+		t.Fatalf("%s trailer = %v, want false", ErrorRetryableTrailer, got)
+	}
+	if got := stream.trailer.Get(ErrorDetailsTrailer); len(got) != 0 {
+		t.Fatalf("%s trailer = %v for an error without details", ErrorDetailsTrailer, got)
+	}
+}
@@ -0,0 +1,354 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// eventQueueSize is the number of pending events buffered per tenant.
+	// Events arriving while a tenant's queue is full are rejected with a
+	// retryable RESOURCE_EXHAUSTED acknowledgement for the host to redeliver.
+	eventQueueSize = 64
+
+	// eventHistorySize is the number of recently acknowledged event IDs kept
+	// to detect events redelivered by the host after a reconnect.
+	eventHistorySize = 1024
+)
+
+// StreamEvents implements PluginExecutionServiceServer.StreamEvents.
+// Events are dispatched to plugins implementing EventPlugin, in order per tenant.
+// Each event is acknowledged on the stream once its handler returns.
+func (s *Server) StreamEvents(stream pluginpb.PluginExecutionService_StreamEventsServer) error {
+	_, handlesEvents := s.plugin.(EventPlugin)
+	_, stateful := s.plugin.(StatefulPlugin)
+	if !handlesEvents && !stateful {
+		return status.Error(codes.Unimplemented, "plugin does not support events")
+	}
+
+	if !s.events.attach(stream) {
+		return status.Error(codes.Unavailable, "plugin is shutting down")
+	}
+	defer s.events.detach(stream)
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			event, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			s.events.enqueue(event)
+		}
+	}()
+
+	select {
+	case err := <-recvErr:
+		// The host closing or dropping the stream is not an error; pending
+		// events keep their per-tenant order and are acknowledged on the
+		// next stream the host opens.
+		if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+			return nil
+		}
+		return err
+	case <-s.events.done:
+		return nil
+	}
+}
+
+// eventDispatcher delivers events to the plugin.
+// Events are processed sequentially per tenant and concurrently across tenants.
+// The dispatcher outlives individual streams so that reconnects do not lose
+// or reorder pending events.
+type eventDispatcher struct {
+	server *Server
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	queues  map[uuid.UUID]chan *pluginpb.PluginEvent
+	pending map[string]struct{}
+	history map[string]struct{}
+	order   []string
+	closed  bool
+	wg      sync.WaitGroup
+
+	sendMu sync.Mutex
+	stream pluginpb.PluginExecutionService_StreamEventsServer
+}
+
+// newEventDispatcher creates a new event dispatcher for the server.
+func newEventDispatcher(server *Server) *eventDispatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &eventDispatcher{
+		server:  server,
+		ctx:     ctx,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		queues:  make(map[uuid.UUID]chan *pluginpb.PluginEvent),
+		pending: make(map[string]struct{}),
+		history: make(map[string]struct{}),
+	}
+}
+
+// attach makes stream the destination for acknowledgements.
+// Returns false if the dispatcher has been closed.
+func (d *eventDispatcher) attach(stream pluginpb.PluginExecutionService_StreamEventsServer) bool {
+	d.mu.Lock()
+	closed := d.closed
+	d.mu.Unlock()
+	if closed {
+		return false
+	}
+
+	d.sendMu.Lock()
+	d.stream = stream
+	d.sendMu.Unlock()
+	return true
+}
+
+// detach removes stream as the destination for acknowledgements,
+// unless a newer stream has already replaced it.
+func (d *eventDispatcher) detach(stream pluginpb.PluginExecutionService_StreamEventsServer) {
+	d.sendMu.Lock()
+	if d.stream == stream {
+		d.stream = nil
+	}
+	d.sendMu.Unlock()
+}
+
+// enqueue queues an event for delivery to its tenant's worker.
+// It never blocks, so that a slow tenant cannot stall the events of others.
+func (d *eventDispatcher) enqueue(event *pluginpb.PluginEvent) {
+	tenantID, err := uuid.Parse(event.TenantId)
+	if err != nil {
+		d.ack(event.EventId, InvalidArgument("invalid tenant ID: %v", err).pluginError())
+		return
+	}
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	// Events without an ID cannot be told apart, so they are never deduplicated
+	dedup := event.EventId != ""
+	if _, seen := d.history[event.EventId]; dedup && seen {
+		// Already handled; the acknowledgement was lost with the previous stream.
+		d.mu.Unlock()
+		d.ack(event.EventId, nil)
+		return
+	}
+	if _, queued := d.pending[event.EventId]; dedup && queued {
+		// Still being handled; it will be acknowledged when done.
+		d.mu.Unlock()
+		return
+	}
+
+	queue, exists := d.queues[tenantID]
+	if !exists {
+		queue = make(chan *pluginpb.PluginEvent, eventQueueSize)
+		d.queues[tenantID] = queue
+		d.wg.Add(1)
+		go d.run(tenantID, queue)
+	}
+
+	// Queues are only closed with d.mu held, so sending under it is safe.
+	select {
+	case queue <- event:
+		if dedup {
+			d.pending[event.EventId] = struct{}{}
+		}
+		d.mu.Unlock()
+	default:
+		d.mu.Unlock()
+		d.ack(event.EventId, ResourceExhausted("event queue of tenant %s is full", tenantID).pluginError())
+	}
+}
+
+// run processes a tenant's events in order until the queue is drained or closed.
+// A drained queue is removed, so that idle tenants do not keep a worker;
+// the tenant's next event starts a new one.
+func (d *eventDispatcher) run(tenantID uuid.UUID, queue <-chan *pluginpb.PluginEvent) {
+	defer d.wg.Done()
+
+	for event := range queue {
+		// Events left over after shutdown cancelled the handlers are not
+		// acknowledged; the host redelivers them.
+		if d.ctx.Err() == nil {
+			d.process(tenantID, event)
+		}
+
+		// Events are only queued with d.mu held, so an empty queue stays empty
+		d.mu.Lock()
+		if len(queue) == 0 && !d.closed {
+			delete(d.queues, tenantID)
+			d.mu.Unlock()
+			return
+		}
+		d.mu.Unlock()
+	}
+}
+
+// process handles an event and acknowledges it.
+func (d *eventDispatcher) process(tenantID uuid.UUID, event *pluginpb.PluginEvent) {
+	var pluginErr *pluginpb.PluginError
+	if err := d.handle(tenantID, event); err != nil {
+		pluginErr = toPluginError(err, CodeEventError)
+	}
+
+	if event.EventId != "" {
+		d.mu.Lock()
+		delete(d.pending, event.EventId)
+		d.remember(event.EventId)
+		d.mu.Unlock()
+	}
+
+	d.ack(event.EventId, pluginErr)
+}
+
+// handle decodes an event and passes it to the tenant's instance or the plugin.
+func (d *eventDispatcher) handle(tenantID uuid.UUID, event *pluginpb.PluginEvent) error {
+	pluginID, err := uuid.Parse(event.PluginId)
+	if err != nil {
+		return fmt.Errorf("invalid plugin ID: %w", err)
+	}
+
+	key := instanceKey{tenantID: tenantID, pluginID: pluginID}
+	handler, entry, err := d.server.eventHandler(key)
+	if err != nil {
+		return err
+	}
+
+	// Keep the tenant's instance from being disabled while the event is handled
+	ctx, endCall, err := entry.begin(d.ctx)
+	if err != nil {
+		return err
+	}
+	defer endCall()
+
+	evt := &Event{
+		ID:        event.EventId,
+		Type:      event.EventType,
+		TenantID:  tenantID,
+		PluginID:  pluginID,
+		Timestamp: time.UnixMilli(event.Timestamp),
+		Payload:   event.Payload,
+	}
+
+	pluginCtx := d.server.newContext(ctx, key)
+	err = recoverPanic(pluginCtx.localLogger(), "event "+evt.Type, func() error {
+		return handler.HandleEvent(pluginCtx, evt)
+	})
+	d.server.panics.observe(err)
+	return err
+}
+
+// eventHandler returns what handles events for the tenant: its live
+// instance if the instance handles events, otherwise the plugin itself.
+// Stateful plugins only handle events of enabled tenants; their instance
+// entry is returned for the event to be tracked against.
+func (s *Server) eventHandler(key instanceKey) (EventInstance, *instanceEntry, error) {
+	eventPlugin, handlesEvents := s.plugin.(EventPlugin)
+	if _, stateful := s.plugin.(StatefulPlugin); !stateful {
+		if !handlesEvents {
+			return nil, nil, NewError(CodeNotSupported, "plugin does not support events")
+		}
+		return eventPlugin, nil, nil
+	}
+
+	entry, exists := s.instances.get(key)
+	if !exists {
+		return nil, nil, FailedPrecondition("plugin is not enabled for tenant %s", key.tenantID)
+	}
+	if handler, ok := entry.instance.(EventInstance); ok {
+		return handler, entry, nil
+	}
+	if !handlesEvents {
+		return nil, nil, NewError(CodeNotSupported, "plugin does not support events")
+	}
+	return eventPlugin, entry, nil
+}
+
+// remember records an acknowledged event ID, evicting the oldest when full.
+// Must be called with d.mu held.
+func (d *eventDispatcher) remember(eventID string) {
+	if len(d.order) >= eventHistorySize {
+		delete(d.history, d.order[0])
+		d.order = d.order[1:]
+	}
+	d.history[eventID] = struct{}{}
+	d.order = append(d.order, eventID)
+}
+
+// ack sends an acknowledgement on the attached stream.
+// Acknowledgements are dropped while no stream is attached; the host
+// redelivers unacknowledged events when it reconnects.
+func (d *eventDispatcher) ack(eventID string, pluginErr *pluginpb.PluginError) {
+	d.sendMu.Lock()
+	defer d.sendMu.Unlock()
+
+	if d.stream == nil {
+		return
+	}
+	// Send errors mean the stream is gone; the host will reconnect.
+	_ = d.stream.Send(&pluginpb.PluginEventAck{
+		EventId: eventID,
+		Success: pluginErr == nil,
+		Error:   pluginErr,
+	})
+}
+
+// close stops accepting events and waits for queued events to be handled.
+// Handlers still running when ctx expires are cancelled; close then waits
+// at most drainGracePeriod for them to return.
+func (d *eventDispatcher) close(ctx context.Context) {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return
+	}
+	d.closed = true
+	for _, queue := range d.queues {
+		close(queue)
+	}
+	d.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+	case <-ctx.Done():
+		d.cancel()
+		waitGrace(finished)
+	}
+	d.cancel()
+	close(d.done)
+}
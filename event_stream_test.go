@@ -0,0 +1,323 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+)
+
+// fakeEventStream is an events stream fed by the test.
+type fakeEventStream struct {
+	grpc.ServerStream
+	events chan *pluginpb.PluginEvent
+	acks   chan *pluginpb.PluginEventAck
+}
+
+func newFakeEventStream() *fakeEventStream {
+	return &fakeEventStream{
+		events: make(chan *pluginpb.PluginEvent, 256),
+		acks:   make(chan *pluginpb.PluginEventAck, 256),
+	}
+}
+
+func (f *fakeEventStream) Context() context.Context {
+	return context.Background()
+}
+
+func (f *fakeEventStream) Recv() (*pluginpb.PluginEvent, error) {
+	event, ok := <-f.events
+	if !ok {
+		return nil, io.EOF
+	}
+	return event, nil
+}
+
+func (f *fakeEventStream) Send(ack *pluginpb.PluginEventAck) error {
+	f.acks <- ack
+	return nil
+}
+
+// nextAck waits for the next acknowledgement sent on the stream.
+func (f *fakeEventStream) nextAck(t *testing.T) *pluginpb.PluginEventAck {
+	t.Helper()
+	select {
+	case ack := <-f.acks:
+		return ack
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for an acknowledgement")
+		return nil
+	}
+}
+
+// event builds an event of the given type for the target.
+func (tt testTarget) event(t *testing.T, id, eventType string, payload interface{}) *pluginpb.PluginEvent {
+	t.Helper()
+	data, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal payload: %v", err)
+	}
+	return &pluginpb.PluginEvent{
+		EventId:   id,
+		TenantId:  tt.tenantID.String(),
+		PluginId:  tt.pluginID.String(),
+		EventType: eventType,
+		Payload:   data,
+		Timestamp: time.Now().UnixMilli(),
+	}
+}
+
+// serveEvents runs StreamEvents on stream until the test ends.
+func serveEvents(t *testing.T, server *Server, stream *fakeEventStream) {
+	t.Helper()
+	done := make(chan error, 1)
+	go func() { done <- server.StreamEvents(stream) }()
+	t.Cleanup(func() {
+		close(stream.events)
+		<-done
+	})
+}
+
+func TestStreamEventsDeliversInOrderPerTenant(t *testing.T) {
+	plugin := NewBasePlugin()
+	var mu sync.Mutex
+	var joined []string
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *UserJoinedEvent) error {
+		mu.Lock()
+		defer mu.Unlock()
+		joined = append(joined, evt.UserID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+
+	target := newTestTarget()
+	for i := 0; i < 10; i++ {
+		stream.events <- target.event(t, fmt.Sprint("evt-", i), EventUserJoined, UserJoinedEvent{UserID: fmt.Sprint("user-", i)})
+	}
+
+	for i := 0; i < 10; i++ {
+		ack := stream.nextAck(t)
+		if want := fmt.Sprint("evt-", i); ack.EventId != want || !ack.Success {
+			t.Fatalf("ack %d = %s (success %v), want successful %s", i, ack.EventId, ack.Success, want)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i, userID := range joined {
+		if want := fmt.Sprint("user-", i); userID != want {
+			t.Fatalf("event %d handled for %s, want %s", i, userID, want)
+		}
+	}
+}
+
+func TestStreamEventsHandlerError(t *testing.T) {
+	plugin := NewBasePlugin()
+	if err := plugin.OnEvent(EventSongPlayed, func(ctx *Context, evt *SongPlayedEvent) error {
+		return NotFound("song %s", evt.SongID)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+
+	target := newTestTarget()
+	stream.events <- target.event(t, "played", EventSongPlayed, SongPlayedEvent{SongID: "s1"})
+	stream.events <- target.event(t, "joined", EventUserJoined, UserJoinedEvent{UserID: "u1"})
+
+	ack := stream.nextAck(t)
+	if ack.Success || ack.Error.GetCode() != string(CodeNotFound) {
+		t.Fatalf("handler error acknowledged as %+v, want NOT_FOUND", ack)
+	}
+	ack = stream.nextAck(t)
+	if ack.Success || ack.Error.GetCode() != string(CodeNotSupported) {
+		t.Fatalf("unhandled event acknowledged as %+v, want NOT_SUPPORTED", ack)
+	}
+}
+
+func TestStreamEventsRedeliveryIsAcknowledgedOnce(t *testing.T) {
+	plugin := NewBasePlugin()
+	var handled int
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		handled++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	first := newFakeEventStream()
+	serveEvents(t, server, first)
+	first.events <- target.event(t, "evt-1", EventUserJoined, UserJoinedEvent{})
+	first.nextAck(t)
+
+	// The host reconnects and redelivers the event
+	second := newFakeEventStream()
+	serveEvents(t, server, second)
+	second.events <- target.event(t, "evt-1", EventUserJoined, UserJoinedEvent{})
+	if ack := second.nextAck(t); ack.EventId != "evt-1" || !ack.Success {
+		t.Fatalf("redelivered event acknowledged as %+v", ack)
+	}
+	if handled != 1 {
+		t.Fatalf("event handled %d times, want 1", handled)
+	}
+}
+
+func TestStreamEventsWithoutIDAreNotDeduplicated(t *testing.T) {
+	plugin := NewBasePlugin()
+	var mu sync.Mutex
+	var handled int
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+
+	target := newTestTarget()
+	stream.events <- target.event(t, "", EventUserJoined, UserJoinedEvent{})
+	stream.events <- target.event(t, "", EventUserJoined, UserJoinedEvent{})
+	for i := 0; i < 2; i++ {
+		if ack := stream.nextAck(t); !ack.Success {
+			t.Fatalf("event without ID acknowledged as %+v", ack)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if handled != 2 {
+		t.Fatalf("events without ID handled %d times, want 2", handled)
+	}
+}
+
+func TestStreamEventsReapsIdleTenantQueues(t *testing.T) {
+	plugin := NewBasePlugin()
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+	queues := func() int {
+		server.events.mu.Lock()
+		defer server.events.mu.Unlock()
+		return len(server.events.queues)
+	}
+
+	target := newTestTarget()
+	stream.events <- target.event(t, "evt-1", EventUserJoined, UserJoinedEvent{})
+	stream.nextAck(t)
+	waitFor(t, func() bool { return queues() == 0 })
+
+	// The tenant's next event starts a new worker
+	stream.events <- target.event(t, "evt-2", EventUserJoined, UserJoinedEvent{})
+	if ack := stream.nextAck(t); ack.EventId != "evt-2" || !ack.Success {
+		t.Fatalf("event after reaping acknowledged as %+v", ack)
+	}
+}
+
+func TestStreamEventsFullQueueDoesNotStallOtherTenants(t *testing.T) {
+	plugin := NewBasePlugin()
+	slow, fast := newTestTarget(), newTestTarget()
+	release := make(chan struct{})
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		if evt.TenantID == slow.tenantID {
+			<-release
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+	defer close(release)
+
+	// One event blocks the handler, eventQueueSize more fill the queue
+	for i := 0; i <= eventQueueSize+1; i++ {
+		stream.events <- slow.event(t, fmt.Sprint("slow-", i), EventUserJoined, UserJoinedEvent{})
+	}
+	stream.events <- fast.event(t, "fast", EventUserJoined, UserJoinedEvent{})
+
+	var rejected, fastAcked bool
+	for !rejected || !fastAcked {
+		ack := stream.nextAck(t)
+		switch {
+		case ack.EventId == "fast" && ack.Success:
+			fastAcked = true
+		case ack.Error.GetCode() == string(CodeResourceExhausted):
+			rejected = true
+		default:
+			t.Fatalf("unexpected acknowledgement %+v", ack)
+		}
+	}
+}
+
+func TestEventDispatcherCloseCancelsStuckHandlers(t *testing.T) {
+	plugin := NewBasePlugin()
+	started := make(chan struct{})
+	cancelled := make(chan struct{})
+	if err := plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		close(started)
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	server := newTestServer(t, plugin)
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+
+	stream.events <- newTestTarget().event(t, "stuck", EventUserJoined, UserJoinedEvent{})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closed := make(chan struct{})
+	go func() {
+		server.events.close(ctx)
+		close(closed)
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("close did not return after its context expired")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(5 * time.Second):
+		t.Fatal("stuck handler was not cancelled")
+	}
+}
@@ -0,0 +1,195 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
+)
+
+// Event types delivered by the host over the events stream.
+const (
+	EventQueueChanged = "queue.changed"
+	EventSongPlayed   = "song.played"
+	EventUserJoined   = "user.joined"
+)
+
+// Event represents an event delivered to a plugin by the host.
+type Event struct {
+	ID        string          // Unique event ID, used for acknowledgements
+	Type      string          // Event type (e.g., "queue.changed")
+	TenantID  uuid.UUID       // Tenant the event belongs to
+	PluginID  uuid.UUID       // Plugin the event is addressed to
+	Timestamp time.Time       // Time the event occurred on the host
+	Payload   json.RawMessage // JSON-encoded event payload
+}
+
+// Decode unmarshals the event payload into v.
+func (e *Event) Decode(v interface{}) error {
+	if len(e.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s event payload: %w", e.Type, err)
+	}
+	return nil
+}
+
+// QueueChangedEvent is the payload of an EventQueueChanged event.
+type QueueChangedEvent struct {
+	Action   string          `json:"action"`         // "added", "removed", "reordered" or "cleared"
+	Position int             `json:"position"`       // Position affected by the change
+	Item     *stub.QueueItem `json:"item,omitempty"` // Queue item affected by the change, if any
+}
+
+// SongPlayedEvent is the payload of an EventSongPlayed event.
+type SongPlayedEvent struct {
+	SongID        string    `json:"song_id"`
+	QueueItemID   string    `json:"queue_item_id,omitempty"`
+	RequesterName string    `json:"requester_name,omitempty"`
+	Song          stub.Song `json:"song,omitempty"`
+}
+
+// UserJoinedEvent is the payload of an EventUserJoined event.
+type UserJoinedEvent struct {
+	UserID string        `json:"user_id"`
+	User   stub.UserInfo `json:"user,omitempty"`
+}
+
+// EventPlugin handles events delivered over the events stream.
+type EventPlugin interface {
+	Plugin
+
+	// HandleEvent handles a single event.
+	// Events of the same tenant are delivered in order, one at a time.
+	// Returning an error reports a failed acknowledgement to the host.
+	HandleEvent(ctx *Context, event *Event) error
+}
+
+// EventHandler represents an event handler function.
+// Supported signatures:
+//   - func(ctx *Context, event *Event) error
+//   - func(ctx *Context, payload *T) error
+type EventHandler interface{}
+
+// registeredEventHandler holds a registered event handler.
+type registeredEventHandler struct {
+	handler     EventHandler
+	payloadType reflect.Type // nil if handler takes the raw *Event
+}
+
+// EventRouter manages event handler registration and dispatch.
+type EventRouter struct {
+	mu       sync.RWMutex
+	handlers map[string]*registeredEventHandler
+}
+
+// NewEventRouter creates a new event router.
+func NewEventRouter() *EventRouter {
+	return &EventRouter{
+		handlers: make(map[string]*registeredEventHandler),
+	}
+}
+
+// On registers a handler for the given event type.
+// Returns an error if a handler is already registered or the handler signature is invalid.
+func (r *EventRouter) On(eventType string, handler EventHandler) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.handlers[eventType]; exists {
+		return fmt.Errorf("handler for event %q already registered", eventType)
+	}
+
+	handlerVal := reflect.ValueOf(handler)
+	handlerType := handlerVal.Type()
+
+	if handlerType.Kind() != reflect.Func {
+		return fmt.Errorf("handler must be a function, got %s", handlerType.Kind())
+	}
+	if handlerType.NumIn() != 2 {
+		return fmt.Errorf("handler must have 2 inputs, got %d", handlerType.NumIn())
+	}
+	if handlerType.NumOut() != 1 {
+		return fmt.Errorf("handler must have 1 output (error), got %d", handlerType.NumOut())
+	}
+
+	// First input must be *Context
+	ctxType := handlerType.In(0)
+	if ctxType.Kind() != reflect.Ptr || ctxType.Elem().Name() != "Context" {
+		return fmt.Errorf("first handler argument must be *Context, got %s", ctxType)
+	}
+
+	// Output must implement error
+	errType := handlerType.Out(0)
+	if !errType.Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		return fmt.Errorf("handler return must be error, got %s", errType)
+	}
+
+	// Second input is either *Event or a pointer to a typed payload
+	payloadType := handlerType.In(1)
+	if payloadType.Kind() != reflect.Ptr {
+		return fmt.Errorf("second handler argument must be a pointer, got %s", payloadType)
+	}
+
+	registered := &registeredEventHandler{handler: handler}
+	if payloadType != reflect.TypeOf(&Event{}) {
+		registered.payloadType = payloadType.Elem()
+	}
+
+	r.handlers[eventType] = registered
+	return nil
+}
+
+// Dispatch delivers an event to its registered handler.
+// Events without a registered handler fail with a NOT_SUPPORTED error, so
+// that the host does not mistake them for handled events.
+func (r *EventRouter) Dispatch(ctx *Context, event *Event) error {
+	r.mu.RLock()
+	registered, exists := r.handlers[event.Type]
+	r.mu.RUnlock()
+
+	if !exists {
+		return NewError(CodeNotSupported, "no handler registered for event %q", event.Type)
+	}
+
+	arg := reflect.ValueOf(event)
+	if registered.payloadType != nil {
+		payloadPtr := reflect.New(registered.payloadType)
+		if err := event.Decode(payloadPtr.Interface()); err != nil {
+			return err
+		}
+		arg = payloadPtr
+	}
+
+	results := reflect.ValueOf(registered.handler).Call([]reflect.Value{reflect.ValueOf(ctx), arg})
+	if !results[0].IsNil() {
+		if err, ok := results[0].Interface().(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasHandler checks if a handler is registered for the event type.
+func (r *EventRouter) HasHandler(eventType string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, exists := r.handlers[eventType]
+	return exists
+}
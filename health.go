@@ -0,0 +1,189 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc/connectivity"
+)
+
+// DefaultHealthCacheTTL is how long a health check result is reused for
+// subsequent host probes.
+const DefaultHealthCacheTTL = 5 * time.Second
+
+// HealthChecker is implemented by plugins that report their own health,
+// for example whether an upstream API is reachable.
+type HealthChecker interface {
+	// CheckHealth returns an error if the plugin cannot serve requests.
+	CheckHealth(ctx context.Context) error
+}
+
+// HealthCheckFunc is a named health check.
+// It returns an error if the checked dependency is unhealthy.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthChecks is a registry of named health checks.
+type HealthChecks struct {
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]HealthCheckFunc
+}
+
+// NewHealthChecks creates a new health check registry.
+func NewHealthChecks() *HealthChecks {
+	return &HealthChecks{
+		checks: make(map[string]HealthCheckFunc),
+	}
+}
+
+// Register registers a named health check.
+// Returns an error if a check with the same name is already registered.
+func (h *HealthChecks) Register(name string, check HealthCheckFunc) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, exists := h.checks[name]; exists {
+		return fmt.Errorf("health check %q already registered", name)
+	}
+	h.names = append(h.names, name)
+	h.checks[name] = check
+	return nil
+}
+
+// CheckHealth runs all registered checks in registration order.
+// Returns an error naming every failed check, or nil if all checks pass.
+func (h *HealthChecks) CheckHealth(ctx context.Context) error {
+	h.mu.RLock()
+	names := append([]string(nil), h.names...)
+	checks := make([]HealthCheckFunc, 0, len(names))
+	for _, name := range names {
+		checks = append(checks, h.checks[name])
+	}
+	h.mu.RUnlock()
+
+	var errs []error
+	for i, check := range checks {
+		if err := check(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// healthCache caches the server's health status for a TTL.
+// Checks run outside the lock, so that a slow check does not block probes.
+type healthCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	checkedAt time.Time
+	status    pluginpb.HealthCheckResponse_ServingStatus
+	running   chan struct{} // closed when the running check ends, nil if none
+	version   uint64        // incremented when the cached status is invalidated
+}
+
+// get returns the cached status, starting check if the cache has expired.
+// While a check runs, probes get the previous status, or wait for the
+// check until ctx is done if there is none.
+func (c *healthCache) get(ctx context.Context, check func(ctx context.Context) error) pluginpb.HealthCheckResponse_ServingStatus {
+	c.mu.Lock()
+	if !c.checkedAt.IsZero() && time.Since(c.checkedAt) < c.ttl {
+		defer c.mu.Unlock()
+		return c.status
+	}
+
+	running := c.running
+	if running == nil {
+		// The check outlives the probe that started it, for the others
+		running = make(chan struct{})
+		c.running = running
+		go c.refresh(context.WithoutCancel(ctx), check, running, c.version)
+	}
+	if !c.checkedAt.IsZero() {
+		defer c.mu.Unlock()
+		return c.status
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-running:
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.status
+	case <-ctx.Done():
+		return pluginpb.HealthCheckResponse_UNKNOWN
+	}
+}
+
+// refresh runs check and caches its status, unless the cache was
+// invalidated meanwhile.
+func (c *healthCache) refresh(ctx context.Context, check func(ctx context.Context) error, running chan struct{}, version uint64) {
+	status := pluginpb.HealthCheckResponse_SERVING
+	if err := check(ctx); err != nil {
+		status = pluginpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	c.mu.Lock()
+	c.status = status
+	if c.version == version {
+		c.checkedAt = time.Now()
+	}
+	c.running = nil
+	c.mu.Unlock()
+	close(running)
+}
+
+// invalidate makes the next probe run the checks again.
+func (c *healthCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checkedAt = time.Time{}
+	c.version++
+}
+
+// checkHealth combines the server's own state with the plugin's health checks.
+func (s *Server) checkHealth(ctx context.Context) error {
+	initialized, initErr := s.initState()
+	if !initialized {
+		return fmt.Errorf("plugin is not initialized")
+	}
+	if initErr != nil {
+		return fmt.Errorf("initialization failed: %w", initErr)
+	}
+
+	if s.inflight.isDraining() {
+		return fmt.Errorf("plugin is shutting down")
+	}
+
+	if s.capabilitiesConn != nil {
+		switch state := s.capabilitiesConn.GetState(); state {
+		case connectivity.TransientFailure, connectivity.Shutdown:
+			return fmt.Errorf("capabilities connection is %s", state)
+		case connectivity.Idle:
+			// Connections are established lazily; start connecting so the
+			// next probe reflects the real state.
+			s.capabilitiesConn.Connect()
+		}
+	}
+
+	if checker, ok := s.plugin.(HealthChecker); ok {
+		return recoverPanic(s.config.logger, "CheckHealth", func() error {
+			return checker.CheckHealth(ctx)
+		})
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// failingInitPlugin fails to initialize.
+type failingInitPlugin struct {
+	BasePlugin
+}
+
+func (p *failingInitPlugin) Initialize(ctx *Context) error {
+	return errors.New("missing credentials")
+}
+
+// newHealthServer creates a test server without a capabilities connection,
+// so that only the plugin's own state is reported.
+func newHealthServer(t *testing.T, plugin Plugin, opts ...ServeOption) *Server {
+	t.Helper()
+
+	server := newTestServer(t, plugin, opts...)
+	must(t, server.capabilitiesConn.Close())
+	server.capabilitiesConn = nil
+	return server
+}
+
+// initialize runs InitializePlugin for the target.
+func (tt testTarget) initialize(t *testing.T, server *Server) *pluginpb.PluginError {
+	t.Helper()
+
+	resp, err := server.InitializePlugin(testContext(t), &pluginpb.InitializePluginRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+	})
+	if err != nil {
+		t.Fatalf("InitializePlugin: %v", err)
+	}
+	return resp.GetError()
+}
+
+// healthStatus probes server's health with ctx.
+func healthStatus(t *testing.T, ctx context.Context, server *Server) pluginpb.HealthCheckResponse_ServingStatus {
+	t.Helper()
+
+	resp, err := server.HealthCheck(ctx, &pluginpb.HealthCheckRequest{})
+	if err != nil {
+		t.Fatalf("HealthCheck: %v", err)
+	}
+	return resp.GetStatus()
+}
+
+func TestHealthCheckNotServingBeforeInitialize(t *testing.T) {
+	server := newHealthServer(t, &BasePlugin{})
+
+	if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status before initialization = %v, want NOT_SERVING", status)
+	}
+
+	if perr := newTestTarget().initialize(t, server); perr != nil {
+		t.Fatalf("InitializePlugin error: %v", perr)
+	}
+	// Initialization invalidates the cached status
+	if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_SERVING {
+		t.Fatalf("status after initialization = %v, want SERVING", status)
+	}
+}
+
+func TestHealthCheckNotServingAfterFailedInitialize(t *testing.T) {
+	server := newHealthServer(t, &failingInitPlugin{})
+
+	if perr := newTestTarget().initialize(t, server); perr == nil {
+		t.Fatal("InitializePlugin succeeded, want an error")
+	}
+	if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING", status)
+	}
+}
+
+func TestHealthCheckRegisteredCheckFails(t *testing.T) {
+	plugin := &BasePlugin{}
+	must(t, plugin.RegisterHealthCheck("upstream", func(ctx context.Context) error {
+		return errors.New("unreachable")
+	}))
+	server := newHealthServer(t, plugin)
+	newTestTarget().initialize(t, server)
+
+	if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("status = %v, want NOT_SERVING", status)
+	}
+}
+
+func TestHealthCheckCachesResult(t *testing.T) {
+	var calls atomic.Int32
+	plugin := &BasePlugin{}
+	must(t, plugin.RegisterHealthCheck("counted", func(ctx context.Context) error {
+		calls.Add(1)
+		return nil
+	}))
+	server := newHealthServer(t, plugin, WithHealthCacheTTL(time.Hour))
+	newTestTarget().initialize(t, server)
+
+	for i := 0; i < 3; i++ {
+		if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_SERVING {
+			t.Fatalf("probe %d: status = %v, want SERVING", i, status)
+		}
+	}
+	if got := calls.Load(); got != 1 {
+		t.Fatalf("check ran %d times within the TTL, want 1", got)
+	}
+}
+
+func TestHealthCheckSlowCheckDoesNotBlockProbes(t *testing.T) {
+	var slow atomic.Bool
+	release := make(chan struct{})
+	plugin := &BasePlugin{}
+	must(t, plugin.RegisterHealthCheck("slow", func(ctx context.Context) error {
+		if slow.Load() {
+			<-release
+		}
+		return nil
+	}))
+	server := newHealthServer(t, plugin, WithHealthCacheTTL(time.Millisecond))
+	defer close(release)
+	newTestTarget().initialize(t, server)
+
+	if status := healthStatus(t, testContext(t), server); status != pluginpb.HealthCheckResponse_SERVING {
+		t.Fatalf("first probe: status = %v, want SERVING", status)
+	}
+	slow.Store(true)
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired status is reported while the slow check runs
+	ctx, cancel := context.WithTimeout(testContext(t), time.Second)
+	defer cancel()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := server.HealthCheck(ctx, &pluginpb.HealthCheckRequest{})
+			if err != nil || resp.GetStatus() != pluginpb.HealthCheckResponse_SERVING {
+				t.Errorf("probe during slow check = %v, %v, want SERVING", resp.GetStatus(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		t.Fatal("probes waited for the slow check")
+	}
+}
+
+func TestHealthCheckFirstProbeHonoursDeadline(t *testing.T) {
+	release := make(chan struct{})
+	plugin := &BasePlugin{}
+	must(t, plugin.RegisterHealthCheck("slow", func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+	server := newHealthServer(t, plugin)
+	defer close(release)
+	newTestTarget().initialize(t, server)
+
+	ctx, cancel := context.WithTimeout(testContext(t), 20*time.Millisecond)
+	defer cancel()
+	if status := healthStatus(t, ctx, server); status != pluginpb.HealthCheckResponse_UNKNOWN {
+		t.Fatalf("status = %v, want UNKNOWN", status)
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/wabisaby/wabisaby-plugin-sdk/devcaps"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+)
+
+// newTestServer creates a server for plugin whose capability calls are
+// answered in-process by a dev mode backend with temporary storage.
+func newTestServer(t *testing.T, plugin Plugin, opts ...ServeOption) *Server {
+	t.Helper()
+
+	opts = append([]ServeOption{WithCapabilitiesAddr("127.0.0.1:1")}, opts...)
+	server, err := NewServer(plugin, opts...)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	server.capabilitiesClient = backendClient{
+		backend: devcaps.New(devcaps.WithStorageDir(t.TempDir()), devcaps.WithLogOutput(io.Discard)),
+	}
+	t.Cleanup(func() { _ = server.Close() })
+	return server
+}
+
+// testTarget is a tenant/plugin pair commands are executed for in tests.
+type testTarget struct {
+	tenantID uuid.UUID
+	pluginID uuid.UUID
+}
+
+// newTestTarget returns a target with random IDs.
+func newTestTarget() testTarget {
+	return testTarget{tenantID: uuid.New(), pluginID: uuid.New()}
+}
+
+// request builds an ExecuteCommandRequest with JSON-encoded args.
+func (tt testTarget) request(t *testing.T, command string, args ...interface{}) *pluginpb.ExecuteCommandRequest {
+	t.Helper()
+
+	req := &pluginpb.ExecuteCommandRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+		Command:  command,
+	}
+	for _, arg := range args {
+		data, err := json.Marshal(arg)
+		if err != nil {
+			t.Fatalf("marshal argument: %v", err)
+		}
+		req.Args = append(req.Args, data)
+	}
+	return req
+}
+
+// execute runs command on server and decodes its result into result, if
+// not nil. It returns the PluginError of a failed command.
+func (tt testTarget) execute(t *testing.T, server *Server, ctx context.Context, result interface{}, command string, args ...interface{}) *pluginpb.PluginError {
+	t.Helper()
+
+	resp, err := server.ExecuteCommand(ctx, tt.request(t, command, args...))
+	if err != nil {
+		t.Fatalf("ExecuteCommand(%s): %v", command, err)
+	}
+	if pluginErr := resp.GetError(); pluginErr != nil {
+		return pluginErr
+	}
+	if result != nil {
+		if err := json.Unmarshal(resp.GetData(), result); err != nil {
+			t.Fatalf("decode %s result %s: %v", command, resp.GetData(), err)
+		}
+	}
+	return nil
+}
+
+// backendClient calls a dev mode backend directly instead of over gRPC.
+type backendClient struct {
+	backend *devcaps.Backend
+}
+
+func (c backendClient) StorageGet(ctx context.Context, in *pluginpb.StorageGetRequest, _ ...grpc.CallOption) (*pluginpb.StorageGetResponse, error) {
+	return c.backend.StorageGet(ctx, in)
+}
+
+func (c backendClient) StorageSet(ctx context.Context, in *pluginpb.StorageSetRequest, _ ...grpc.CallOption) (*pluginpb.StorageSetResponse, error) {
+	return c.backend.StorageSet(ctx, in)
+}
+
+func (c backendClient) StorageDelete(ctx context.Context, in *pluginpb.StorageDeleteRequest, _ ...grpc.CallOption) (*pluginpb.StorageDeleteResponse, error) {
+	return c.backend.StorageDelete(ctx, in)
+}
+
+func (c backendClient) StorageKeys(ctx context.Context, in *pluginpb.StorageKeysRequest, _ ...grpc.CallOption) (*pluginpb.StorageKeysResponse, error) {
+	return c.backend.StorageKeys(ctx, in)
+}
+
+func (c backendClient) HTTPFetch(ctx context.Context, in *pluginpb.HTTPFetchRequest, _ ...grpc.CallOption) (*pluginpb.HTTPFetchResponse, error) {
+	return c.backend.HTTPFetch(ctx, in)
+}
+
+func (c backendClient) QueueGet(ctx context.Context, in *pluginpb.QueueGetRequest, _ ...grpc.CallOption) (*pluginpb.QueueGetResponse, error) {
+	return c.backend.QueueGet(ctx, in)
+}
+
+func (c backendClient) QueueAdd(ctx context.Context, in *pluginpb.QueueAddRequest, _ ...grpc.CallOption) (*pluginpb.QueueAddResponse, error) {
+	return c.backend.QueueAdd(ctx, in)
+}
+
+func (c backendClient) QueueRemove(ctx context.Context, in *pluginpb.QueueRemoveRequest, _ ...grpc.CallOption) (*pluginpb.QueueRemoveResponse, error) {
+	return c.backend.QueueRemove(ctx, in)
+}
+
+func (c backendClient) QueueReorder(ctx context.Context, in *pluginpb.QueueReorderRequest, _ ...grpc.CallOption) (*pluginpb.QueueReorderResponse, error) {
+	return c.backend.QueueReorder(ctx, in)
+}
+
+func (c backendClient) NotificationSend(ctx context.Context, in *pluginpb.NotificationSendRequest, _ ...grpc.CallOption) (*pluginpb.NotificationSendResponse, error) {
+	return c.backend.NotificationSend(ctx, in)
+}
+
+func (c backendClient) SecretGet(ctx context.Context, in *pluginpb.SecretGetRequest, _ ...grpc.CallOption) (*pluginpb.SecretGetResponse, error) {
+	return c.backend.SecretGet(ctx, in)
+}
+
+func (c backendClient) SecretSet(ctx context.Context, in *pluginpb.SecretSetRequest, _ ...grpc.CallOption) (*pluginpb.SecretSetResponse, error) {
+	return c.backend.SecretSet(ctx, in)
+}
+
+func (c backendClient) SongSearch(ctx context.Context, in *pluginpb.SongSearchRequest, _ ...grpc.CallOption) (*pluginpb.SongSearchResponse, error) {
+	return c.backend.SongSearch(ctx, in)
+}
+
+func (c backendClient) SongGet(ctx context.Context, in *pluginpb.SongGetRequest, _ ...grpc.CallOption) (*pluginpb.SongGetResponse, error) {
+	return c.backend.SongGet(ctx, in)
+}
+
+func (c backendClient) UserGet(ctx context.Context, in *pluginpb.UserGetRequest, _ ...grpc.CallOption) (*pluginpb.UserGetResponse, error) {
+	return c.backend.UserGet(ctx, in)
+}
+
+func (c backendClient) Log(ctx context.Context, in *pluginpb.LogRequest, _ ...grpc.CallOption) (*pluginpb.LogResponse, error) {
+	return c.backend.Log(ctx, in)
+}
+
+// run executes command like execute, failing the test if it fails.
+func (tt testTarget) run(t *testing.T, server *Server, result interface{}, command string, args ...interface{}) {
+	t.Helper()
+	if pluginErr := tt.execute(t, server, context.Background(), result, command, args...); pluginErr != nil {
+		t.Fatalf("%s failed: %s: %s", command, pluginErr.Code, pluginErr.Message)
+	}
+}
+
+// must fails the test on error.
+func must(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// errorCode returns the code of an SDK error, or an empty code.
+func errorCode(err error) ErrorCode {
+	var sdkErr *Error
+	if errors.As(err, &sdkErr) {
+		return sdkErr.Code
+	}
+	return ""
+}
+
+// testContext returns a context cancelled when the test ends.
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	return ctx
+}
+
+// counterValue sums the counters of the snapshot with the given name and
+// labels, ignoring labels not given.
+func counterValue(snapshot MetricsSnapshot, name string, labels map[string]string) uint64 {
+	var value uint64
+	for _, sample := range snapshot.Counters {
+		if sample.Name == name && hasLabels(sample.Labels, labels) {
+			value += sample.Value
+		}
+	}
+	return value
+}
+
+// hasLabels checks if labels contains all of want.
+func hasLabels(labels, want map[string]string) bool {
+	for key, value := range want {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
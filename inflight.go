@@ -0,0 +1,123 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout is how long ShutdownPlugin waits for in-flight
+// commands before cancelling them.
+const DefaultDrainTimeout = 30 * time.Second
+
+// drainGracePeriod is how long draining waits for cancelled executions to
+// return before giving up on them.
+var drainGracePeriod = 5 * time.Second
+
+// errDrainTimeout is the cause of the context of an execution cancelled
+// because it outlived the drain timeout.
+var errDrainTimeout = errors.New("command cancelled after the drain timeout")
+
+// inflightTracker tracks running command executions so that shutdown can
+// wait for them.
+type inflightTracker struct {
+	mu       sync.Mutex
+	draining bool
+	nextID   uint64
+	cancels  map[uint64]context.CancelCauseFunc
+	wg       sync.WaitGroup
+}
+
+// newInflightTracker creates a new in-flight tracker.
+func newInflightTracker() *inflightTracker {
+	return &inflightTracker{
+		cancels: make(map[uint64]context.CancelCauseFunc),
+	}
+}
+
+// begin registers a new execution.
+// It returns a context that is cancelled if the execution outlives the
+// drain timeout, and a function that must be called when the execution ends.
+// Returns false once draining has started.
+func (t *inflightTracker) begin(ctx context.Context) (context.Context, func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.draining {
+		return nil, nil, false
+	}
+
+	callCtx, cancel := context.WithCancelCause(ctx)
+	id := t.nextID
+	t.nextID++
+	t.cancels[id] = cancel
+	t.wg.Add(1)
+
+	done := func() {
+		t.mu.Lock()
+		delete(t.cancels, id)
+		t.mu.Unlock()
+		cancel(nil)
+		t.wg.Done()
+	}
+	return callCtx, done, true
+}
+
+// isDraining checks if draining has started.
+func (t *inflightTracker) isDraining() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.draining
+}
+
+// drain stops accepting new executions and waits for running ones to end.
+// Executions still running when ctx is done are cancelled; drain then waits
+// at most drainGracePeriod for them to return.
+func (t *inflightTracker) drain(ctx context.Context) {
+	t.mu.Lock()
+	t.draining = true
+	t.mu.Unlock()
+
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+
+	select {
+	case <-finished:
+		return
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	for _, cancel := range t.cancels {
+		cancel(errDrainTimeout)
+	}
+	t.mu.Unlock()
+
+	waitGrace(finished)
+}
+
+// waitGrace waits for finished to be closed, at most drainGracePeriod.
+func waitGrace(finished <-chan struct{}) {
+	timer := time.NewTimer(drainGracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-finished:
+	case <-timer.C:
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// shutdownPlugin records whether Shutdown was called.
+type shutdownPlugin struct {
+	*BasePlugin
+	shutdown atomic.Bool
+}
+
+func (p *shutdownPlugin) Shutdown(ctx *Context) error {
+	p.shutdown.Store(true)
+	return nil
+}
+
+// shutdown calls ShutdownPlugin for the target and returns how long it took.
+func (tt testTarget) shutdown(t *testing.T, server *Server) time.Duration {
+	t.Helper()
+	start := time.Now()
+	resp, err := server.ShutdownPlugin(context.Background(), &pluginpb.ShutdownPluginRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+	})
+	if err != nil || resp.GetError() != nil {
+		t.Fatalf("ShutdownPlugin: %v %v", err, resp.GetError())
+	}
+	return time.Since(start)
+}
+
+// blockingPlugin has a "block" command running until released or
+// cancelled, and a "stuck" command ignoring cancellation until released.
+func blockingPlugin(t *testing.T) (*shutdownPlugin, chan struct{}, chan struct{}) {
+	t.Helper()
+	plugin := &shutdownPlugin{BasePlugin: NewBasePlugin()}
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	must(t, plugin.RegisterCommand("block", func(ctx *Context) (interface{}, error) {
+		started <- struct{}{}
+		select {
+		case <-release:
+			return "done", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}))
+	must(t, plugin.RegisterCommand("stuck", func(ctx *Context) (interface{}, error) {
+		started <- struct{}{}
+		<-release
+		return "done", nil
+	}))
+	return plugin, started, release
+}
+
+func TestShutdownWaitsForRunningCommands(t *testing.T) {
+	plugin, started, release := blockingPlugin(t)
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() { result <- target.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		target.shutdown(t, server)
+		close(shutdownDone)
+	}()
+
+	// New commands are rejected while draining
+	for !server.inflight.isDraining() {
+		time.Sleep(time.Millisecond)
+	}
+	if err := target.execute(t, server, context.Background(), nil, "block"); err.GetCode() != string(CodeUnavailable) {
+		t.Fatalf("command during shutdown failed with %+v, want UNAVAILABLE", err)
+	}
+	if plugin.shutdown.Load() {
+		t.Fatal("Shutdown called while a command was running")
+	}
+
+	close(release)
+	if err := <-result; err != nil {
+		t.Fatalf("running command failed with %+v", err)
+	}
+	<-shutdownDone
+	if !plugin.shutdown.Load() {
+		t.Fatal("Shutdown not called")
+	}
+}
+
+func TestShutdownCancelsCommandsAfterDrainTimeout(t *testing.T) {
+	plugin, started, _ := blockingPlugin(t)
+	server := newTestServer(t, plugin, WithDrainTimeout(20*time.Millisecond))
+	target := newTestTarget()
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() { result <- target.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+
+	target.shutdown(t, server)
+	if err := <-result; err.GetCode() != string(CodeCancelled) {
+		t.Fatalf("command outliving the drain timeout failed with %+v, want CANCELLED", err)
+	}
+	if !plugin.shutdown.Load() {
+		t.Fatal("Shutdown not called")
+	}
+}
+
+func TestShutdownGivesUpOnCommandsIgnoringCancellation(t *testing.T) {
+	grace := drainGracePeriod
+	drainGracePeriod = 20 * time.Millisecond
+	defer func() { drainGracePeriod = grace }()
+
+	plugin, started, release := blockingPlugin(t)
+	defer close(release)
+	server := newTestServer(t, plugin, WithDrainTimeout(20*time.Millisecond))
+	target := newTestTarget()
+
+	req := target.request(t, "stuck")
+	go func() { _, _ = server.ExecuteCommand(context.Background(), req) }()
+	<-started
+
+	if took := target.shutdown(t, server); took > time.Second {
+		t.Fatalf("ShutdownPlugin took %s with a command ignoring cancellation", took)
+	}
+	if !plugin.shutdown.Load() {
+		t.Fatal("Shutdown not called")
+	}
+}
+
+func TestShutdownGivesUpOnStuckEventHandlers(t *testing.T) {
+	grace := drainGracePeriod
+	drainGracePeriod = 20 * time.Millisecond
+	defer func() { drainGracePeriod = grace }()
+
+	plugin := &shutdownPlugin{BasePlugin: NewBasePlugin()}
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	must(t, plugin.OnEvent(EventUserJoined, func(ctx *Context, evt *Event) error {
+		close(started)
+		<-release
+		return nil
+	}))
+	server := newTestServer(t, plugin, WithDrainTimeout(20*time.Millisecond))
+	stream := newFakeEventStream()
+	serveEvents(t, server, stream)
+
+	target := newTestTarget()
+	stream.events <- target.event(t, "stuck", EventUserJoined, UserJoinedEvent{})
+	<-started
+
+	if took := target.shutdown(t, server); took > time.Second {
+		t.Fatalf("ShutdownPlugin took %s with a stuck event handler", took)
+	}
+	if !plugin.shutdown.Load() {
+		t.Fatal("Shutdown not called")
+	}
+}
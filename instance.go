@@ -0,0 +1,150 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// StatefulPlugin maintains live state for each tenant it is enabled for.
+type StatefulPlugin interface {
+	Plugin
+
+	// Enable is called when the plugin is enabled for a tenant.
+	// The returned instance holds the tenant's state until Disable is called.
+	Enable(ctx *Context, config map[string]interface{}) (Instance, error)
+
+	// Disable is called when the plugin is disabled for a tenant.
+	// Use this to tear down the tenant's state.
+	Disable(ctx *Context, instance Instance) error
+}
+
+// Instance is the live state of a stateful plugin for a single tenant.
+// Instances implementing CommandInstance or EventInstance receive their
+// tenant's commands or events instead of the plugin.
+type Instance interface{}
+
+// CommandInstance is an Instance that handles its tenant's commands.
+type CommandInstance interface {
+	// ExecuteCommand executes a command with the given arguments.
+	// Returns the result as a JSON-serializable value.
+	ExecuteCommand(ctx *Context, command string, args []interface{}) (interface{}, error)
+}
+
+// EventInstance is an Instance that handles its tenant's events.
+type EventInstance interface {
+	// HandleEvent handles a single event.
+	HandleEvent(ctx *Context, event *Event) error
+}
+
+// instanceNamespace is the UUID namespace instance IDs are derived from.
+var instanceNamespace = uuid.MustParse("fbd401db-c040-462f-bda7-ef78897bf009")
+
+// instanceKey identifies a plugin enabled for a tenant.
+type instanceKey struct {
+	tenantID uuid.UUID
+	pluginID uuid.UUID
+}
+
+// instanceID returns the instance ID for the key.
+// The ID is derived from the tenant and plugin IDs, so it is unique per
+// tenant/plugin pair and stable across re-enables and process restarts.
+func (k instanceKey) instanceID() string {
+	name := make([]byte, 0, 32)
+	name = append(name, k.tenantID[:]...)
+	name = append(name, k.pluginID[:]...)
+	return uuid.NewSHA1(instanceNamespace, name).String()
+}
+
+// instanceEntry holds a live instance with its ID.
+type instanceEntry struct {
+	id       string
+	instance Instance
+
+	// Commands and events using the instance, drained before Disable
+	calls *inflightTracker
+}
+
+// newInstanceEntry creates the entry of a newly enabled instance.
+func newInstanceEntry(key instanceKey, instance Instance) *instanceEntry {
+	return &instanceEntry{
+		id:       key.instanceID(),
+		instance: instance,
+		calls:    newInflightTracker(),
+	}
+}
+
+// begin registers a call using the instance, which a nil entry does not
+// track. It returns a context that is cancelled if the call outlives the
+// drain timeout of Disable, and a function that must be called when the
+// call ends. Fails once the instance is being disabled.
+func (e *instanceEntry) begin(ctx context.Context) (context.Context, func(), error) {
+	if e == nil {
+		return ctx, func() {}, nil
+	}
+	callCtx, done, ok := e.calls.begin(ctx)
+	if !ok {
+		return nil, nil, FailedPrecondition("plugin is being disabled for the tenant")
+	}
+	return callCtx, done, nil
+}
+
+// instanceRegistry keeps the live instances of a stateful plugin.
+type instanceRegistry struct {
+	mu      sync.RWMutex
+	entries map[instanceKey]*instanceEntry
+}
+
+// newInstanceRegistry creates a new instance registry.
+func newInstanceRegistry() *instanceRegistry {
+	return &instanceRegistry{
+		entries: make(map[instanceKey]*instanceEntry),
+	}
+}
+
+// get returns the live instance for the key, if any.
+func (r *instanceRegistry) get(key instanceKey) (*instanceEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, exists := r.entries[key]
+	return entry, exists
+}
+
+// put stores the live instance for the key.
+func (r *instanceRegistry) put(key instanceKey, entry *instanceEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[key] = entry
+}
+
+// remove removes and returns the live instance for the key, if any.
+func (r *instanceRegistry) remove(key instanceKey) (*instanceEntry, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, exists := r.entries[key]
+	delete(r.entries, key)
+	return entry, exists
+}
+
+// keys returns the keys of all live instances.
+func (r *instanceRegistry) keys() []instanceKey {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]instanceKey, 0, len(r.entries))
+	for key := range r.entries {
+		keys = append(keys, key)
+	}
+	return keys
+}
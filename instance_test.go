@@ -0,0 +1,224 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// tenantPlugin is a stateful plugin whose instances remember their config.
+type tenantPlugin struct {
+	*BasePlugin
+
+	mu       sync.Mutex
+	disabled []string
+	started  chan struct{} // receives when a "block" command starts
+	unblock  chan struct{} // ends "block" commands
+}
+
+func newTenantPlugin() *tenantPlugin {
+	return &tenantPlugin{
+		BasePlugin: NewBasePlugin(),
+		started:    make(chan struct{}, 1),
+		unblock:    make(chan struct{}),
+	}
+}
+
+type tenantInstance struct {
+	plugin *tenantPlugin
+	name   string
+}
+
+func (p *tenantPlugin) Enable(ctx *Context, config map[string]interface{}) (Instance, error) {
+	name, _ := config["name"].(string)
+	return &tenantInstance{plugin: p, name: name}, nil
+}
+
+func (p *tenantPlugin) Disable(ctx *Context, instance Instance) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.disabled = append(p.disabled, instance.(*tenantInstance).name)
+	return nil
+}
+
+func (p *tenantPlugin) disabledNames() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.disabled...)
+}
+
+func (i *tenantInstance) ExecuteCommand(ctx *Context, command string, args []interface{}) (interface{}, error) {
+	switch command {
+	case "name":
+		return i.name, nil
+	case "block":
+		i.plugin.started <- struct{}{}
+		select {
+		case <-i.plugin.unblock:
+			return "unblocked", nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return nil, NotFound("unknown command: %s", command)
+}
+
+// enable enables the plugin for the target with the given config.
+func (tt testTarget) enable(t *testing.T, server *Server, config map[string]interface{}) string {
+	t.Helper()
+	var data []byte
+	if config != nil {
+		data, _ = json.Marshal(config)
+	}
+	resp, err := server.EnablePlugin(context.Background(), &pluginpb.EnablePluginRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+		Config:   data,
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("EnablePlugin: %v %v", err, resp.GetError())
+	}
+	return resp.InstanceId
+}
+
+// disable disables the plugin for the target.
+func (tt testTarget) disable(t *testing.T, server *Server) {
+	t.Helper()
+	resp, err := server.DisablePlugin(context.Background(), &pluginpb.DisablePluginRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("DisablePlugin: %v %v", err, resp.GetError())
+	}
+}
+
+func TestEnablePluginInstanceIDs(t *testing.T) {
+	server := newTestServer(t, newTenantPlugin())
+	first, second := newTestTarget(), newTestTarget()
+
+	id := first.enable(t, server, nil)
+	if again := first.enable(t, server, nil); again != id {
+		t.Fatalf("enabling twice returned %s and %s", id, again)
+	}
+	if other := second.enable(t, server, nil); other == id {
+		t.Fatalf("tenants share instance ID %s", id)
+	}
+
+	first.disable(t, server)
+	if reenabled := first.enable(t, server, nil); reenabled != id {
+		t.Fatalf("re-enabled instance ID %s, want stable %s", reenabled, id)
+	}
+}
+
+func TestCommandsRouteToTenantInstance(t *testing.T) {
+	plugin := newTenantPlugin()
+	server := newTestServer(t, plugin)
+	alice, bob := newTestTarget(), newTestTarget()
+	alice.enable(t, server, map[string]interface{}{"name": "alice"})
+	bob.enable(t, server, map[string]interface{}{"name": "bob"})
+
+	var name string
+	if err := alice.execute(t, server, context.Background(), &name, "name"); err != nil || name != "alice" {
+		t.Fatalf("alice's command answered %q (%v)", name, err)
+	}
+
+	bob.disable(t, server)
+	if got := plugin.disabledNames(); len(got) != 1 || got[0] != "bob" {
+		t.Fatalf("disabled instances %v, want [bob]", got)
+	}
+	if err := alice.execute(t, server, context.Background(), &name, "name"); err != nil || name != "alice" {
+		t.Fatalf("alice's command answered %q (%v) after disabling bob", name, err)
+	}
+}
+
+func TestCommandsOfDisabledTenantFail(t *testing.T) {
+	server := newTestServer(t, newTenantPlugin())
+	target := newTestTarget()
+
+	err := target.execute(t, server, context.Background(), nil, "name")
+	if err.GetCode() != string(CodeFailedPrecondition) {
+		t.Fatalf("command of never enabled tenant failed with %+v, want FAILED_PRECONDITION", err)
+	}
+
+	target.enable(t, server, nil)
+	target.disable(t, server)
+	err = target.execute(t, server, context.Background(), nil, "name")
+	if err.GetCode() != string(CodeFailedPrecondition) {
+		t.Fatalf("command of disabled tenant failed with %+v, want FAILED_PRECONDITION", err)
+	}
+}
+
+func TestDisablePluginWaitsForRunningCommands(t *testing.T) {
+	plugin := newTenantPlugin()
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+	target.enable(t, server, map[string]interface{}{"name": "alice"})
+
+	result := make(chan string, 1)
+	go func() {
+		var out string
+		if err := target.execute(t, server, context.Background(), &out, "block"); err != nil {
+			out = err.Code
+		}
+		result <- out
+	}()
+	<-plugin.started
+
+	disabled := make(chan struct{})
+	go func() {
+		target.disable(t, server)
+		close(disabled)
+	}()
+
+	select {
+	case <-disabled:
+		t.Fatal("DisablePlugin returned while a command was running")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if got := plugin.disabledNames(); len(got) != 0 {
+		t.Fatalf("Disable called for %v while a command was running", got)
+	}
+
+	close(plugin.unblock)
+	if out := <-result; out != "unblocked" {
+		t.Fatalf("running command ended with %q", out)
+	}
+	<-disabled
+	if got := plugin.disabledNames(); len(got) != 1 {
+		t.Fatalf("disabled instances %v after the command ended", got)
+	}
+}
+
+func TestDisablePluginCancelsCommandsAfterDrainTimeout(t *testing.T) {
+	plugin := newTenantPlugin()
+	server := newTestServer(t, plugin, WithDrainTimeout(20*time.Millisecond))
+	target := newTestTarget()
+	target.enable(t, server, nil)
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() {
+		result <- target.execute(t, server, context.Background(), nil, "block")
+	}()
+	<-plugin.started
+
+	target.disable(t, server)
+	if err := <-result; err.GetCode() != string(CodeCancelled) {
+		t.Fatalf("command outliving the drain timeout ended with %+v, want CANCELLED", err)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"strings"
+)
+
+// ReservedCommandPrefix prefixes the names of commands reserved by the SDK.
+// Commands with this prefix cannot be registered.
+const ReservedCommandPrefix = "__"
+
+// Reserved introspection commands answered by the router.
+const (
+	// CommandsCommand returns the metadata of all commands, sorted by name.
+	CommandsCommand = "__commands"
+
+	// DescribeCommand returns the metadata of the command named by its
+	// "name" argument.
+	DescribeCommand = "__describe"
+
+	// PingCommand does nothing, for latency checks.
+	PingCommand = "__ping"
+)
+
+// isReservedCommand checks if name uses the reserved command prefix.
+func isReservedCommand(name string) bool {
+	return strings.HasPrefix(name, ReservedCommandPrefix)
+}
+
+// DisableIntrospection stops the router from answering the reserved
+// introspection commands, which then fail as unknown commands.
+func (r *CommandRouter) DisableIntrospection() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.introspectionDisabled = true
+}
+
+// IntrospectionDisabled checks if DisableIntrospection was called.
+func (r *CommandRouter) IntrospectionDisabled() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.introspectionDisabled
+}
+
+// introspectionDisabler is implemented by plugins that can opt out of the
+// introspection commands, such as plugins embedding BasePlugin.
+type introspectionDisabler interface {
+	IntrospectionDisabled() bool
+}
+
+// introspectionDisabled checks if plugin opted out of the introspection
+// commands of the router and the server's __manifest.
+func introspectionDisabled(plugin interface{}) bool {
+	disabler, ok := plugin.(introspectionDisabler)
+	return ok && disabler.IntrospectionDisabled()
+}
+
+// introspect answers a reserved introspection command.
+// Returns false if command is not one, or introspection is disabled.
+func (r *CommandRouter) introspect(command string, args []interface{}) (interface{}, bool, error) {
+	r.mu.RLock()
+	disabled := r.introspectionDisabled
+	r.mu.RUnlock()
+	if disabled {
+		return nil, false, nil
+	}
+
+	switch command {
+	case CommandsCommand:
+		return r.GetCommands(), true, nil
+	case DescribeCommand:
+		name := describeName(args)
+		if name == "" {
+			return nil, true, InvalidArgument("%s requires a command name", DescribeCommand)
+		}
+		metadata, exists := r.GetCommand(name)
+		if !exists {
+			return nil, true, NotFound("unknown command: %s", name)
+		}
+		return metadata, true, nil
+	case PingCommand:
+		return nil, true, nil
+	}
+	return nil, false, nil
+}
+
+// describeName returns the command name passed to the describe command,
+// either as {"name": ...} or as the first positional argument.
+func describeName(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	switch arg := args[0].(type) {
+	case string:
+		return arg
+	case map[string]interface{}:
+		name, _ := arg["name"].(string)
+		return name
+	}
+	return ""
+}
@@ -0,0 +1,176 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// introspectionPlugin has the commands "search" and "download".
+func introspectionPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	noop := func(ctx *Context) (interface{}, error) { return nil, nil }
+	must(t, plugin.RegisterCommand("search", noop, WithDescription("Searches songs")))
+	must(t, plugin.RegisterCommand("download", noop))
+	return plugin
+}
+
+// selfIntrospectingPlugin handles the reserved names itself, answering
+// every command with its name.
+type selfIntrospectingPlugin struct {
+	*BasePlugin
+}
+
+func (p selfIntrospectingPlugin) ExecuteCommand(ctx *Context, command string, args []interface{}) (interface{}, error) {
+	return command, nil
+}
+
+func TestIntrospectionCommands(t *testing.T) {
+	server := newTestServer(t, introspectionPlugin(t))
+	target := newTestTarget()
+
+	var commands []CommandMetadata
+	target.run(t, server, &commands, CommandsCommand)
+	if len(commands) != 2 || commands[0].Name != "download" || commands[1].Name != "search" {
+		t.Fatalf("__commands = %+v, want download and search sorted", commands)
+	}
+
+	var described CommandMetadata
+	target.run(t, server, &described, DescribeCommand, map[string]string{"name": "search"})
+	if described.Name != "search" || described.Description != "Searches songs" {
+		t.Fatalf("__describe = %+v, want the search command", described)
+	}
+	if perr := target.execute(t, server, testContext(t), nil, DescribeCommand, "missing"); perr.GetCode() != string(CodeNotFound) {
+		t.Fatalf("__describe of an unknown command: error = %v, want %s", perr, CodeNotFound)
+	}
+	if perr := target.execute(t, server, testContext(t), nil, DescribeCommand); perr.GetCode() != string(CodeInvalidArgument) {
+		t.Fatalf("__describe without a name: error = %v, want %s", perr, CodeInvalidArgument)
+	}
+
+	target.run(t, server, nil, PingCommand)
+}
+
+func TestReservedCommandsCannotBeRegistered(t *testing.T) {
+	plugin := NewBasePlugin()
+	noop := func(ctx *Context) (interface{}, error) { return nil, nil }
+	for _, name := range []string{PingCommand, ManifestCommand, "__custom"} {
+		if err := plugin.RegisterCommand(name, noop); err == nil {
+			t.Errorf("registering %s succeeded, want it refused", name)
+		}
+	}
+}
+
+func TestDisableIntrospection(t *testing.T) {
+	plugin := selfIntrospectingPlugin{BasePlugin: NewBasePlugin()}
+	plugin.DisableIntrospection()
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	for _, command := range []string{CommandsCommand, DescribeCommand, PingCommand, ManifestCommand} {
+		var result string
+		target.run(t, server, &result, command, "arg")
+		if result != command {
+			t.Errorf("%s = %q, want it handled by the plugin", command, result)
+		}
+	}
+
+	// The server keeps answering the commands the host needs
+	var snapshot MetricsSnapshot
+	target.run(t, server, &snapshot, MetricsCommand)
+	var jobs []Job
+	target.run(t, server, &jobs, JobListCommand)
+	if cancelled := target.cancel(t, server, "req-1"); cancelled != 0 {
+		t.Errorf("__cancel cancelled %d commands, want none", cancelled)
+	}
+}
+
+func TestDisableIntrospectionKeepsJobCommands(t *testing.T) {
+	plugin := jobsPlugin(t)
+	plugin.DisableIntrospection()
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	if perr := target.execute(t, server, testContext(t), nil, CommandsCommand); perr.GetCode() != string(CodeNotFound) {
+		t.Fatalf("__commands with introspection disabled: error = %v, want %s", perr, CodeNotFound)
+	}
+
+	id := target.startJob(t, server, "forever")
+	var job Job
+	target.run(t, server, &job, JobStatusCommand, id)
+	if job.ID != id {
+		t.Fatalf("__job_status = %+v, want job %s", job, id)
+	}
+	target.run(t, server, &job, JobCancelCommand, id)
+	if job = target.awaitJob(t, server, id); job.Status != JobCancelled {
+		t.Fatalf("job = %+v, want cancelled", job)
+	}
+}
+
+func TestReservedCommandsValidateIDs(t *testing.T) {
+	server := newTestServer(t, introspectionPlugin(t))
+
+	for _, command := range []string{MetricsCommand, ManifestCommand, JobListCommand, CancelCommand} {
+		req := newTestTarget().request(t, command, "arg")
+		req.TenantId = "not-a-tenant"
+		resp, err := server.ExecuteCommand(testContext(t), req)
+		must(t, err)
+		if code := resp.GetError().GetCode(); code != string(CodeInvalidArgument) {
+			t.Errorf("%s with an invalid tenant ID: code = %q, want %s", command, code, CodeInvalidArgument)
+		}
+	}
+}
+
+func TestReservedCommandsCountedInMetrics(t *testing.T) {
+	server := newTestServer(t, introspectionPlugin(t))
+	target := newTestTarget()
+
+	target.run(t, server, nil, MetricsCommand)
+	target.run(t, server, nil, JobListCommand)
+
+	snapshot := server.Metrics()
+	for _, command := range []string{MetricsCommand, JobListCommand} {
+		if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": command, "code": outcomeOK}); got != 1 {
+			t.Errorf("%s calls = %d, want 1", command, got)
+		}
+	}
+}
+
+func TestReservedCommandsRefusedDuringShutdown(t *testing.T) {
+	plugin, started, release := blockingPlugin(t)
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	result := make(chan *pluginpb.PluginError, 1)
+	go func() { result <- target.execute(t, server, context.Background(), nil, "block") }()
+	<-started
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		target.shutdown(t, server)
+		close(shutdownDone)
+	}()
+
+	waitFor(t, server.inflight.isDraining)
+	if perr := target.execute(t, server, testContext(t), nil, MetricsCommand); perr.GetCode() != string(CodeUnavailable) {
+		t.Fatalf("__metrics during shutdown: error = %v, want %s", perr, CodeUnavailable)
+	}
+
+	close(release)
+	if perr := <-result; perr != nil {
+		t.Fatalf("running command failed with %v", perr)
+	}
+	<-shutdownDone
+}
@@ -0,0 +1,559 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/wabisaby/wabisaby-plugin-sdk/stub"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// JobStoragePrefix prefixes the storage keys jobs are persisted under.
+// Plugins should not write keys with this prefix.
+const JobStoragePrefix = "__jobs/"
+
+// DefaultJobRetention is how long finished jobs are kept by default.
+const DefaultJobRetention = 7 * 24 * time.Hour
+
+const (
+	// jobHeartbeat is how often the process running a job records in
+	// storage that it is alive.
+	jobHeartbeat = 30 * time.Second
+	// jobLease is how long after its last heartbeat an unfinished job is
+	// taken as interrupted, its process having ended.
+	jobLease = 3 * jobHeartbeat
+)
+
+// Reserved commands managing the jobs of asynchronous commands.
+const (
+	// JobStatusCommand returns the Job named by its "job_id" argument.
+	JobStatusCommand = "__job_status"
+
+	// JobCancelCommand cancels the job named by its "job_id" argument and
+	// returns it.
+	JobCancelCommand = "__job_cancel"
+
+	// JobListCommand returns the jobs of the tenant, newest first,
+	// optionally only those with the status given as "status" argument.
+	JobListCommand = "__job_list"
+)
+
+// JobStatus is the state of a job.
+type JobStatus string
+
+// Job states.
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobSucceeded JobStatus = "succeeded"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Finished checks if the job has ended.
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed || s == JobCancelled
+}
+
+// Job is a run of an asynchronous command.
+type Job struct {
+	ID        string          `json:"id"`
+	Command   string          `json:"command"`
+	RequestID string          `json:"request_id,omitempty"`
+	Status    JobStatus       `json:"status"`
+	Progress  float64         `json:"progress"`
+	Message   string          `json:"message,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     *JobError       `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// JobError is the error a failed job ended with.
+type JobError struct {
+	Code      string            `json:"code"`
+	Message   string            `json:"message"`
+	Retryable bool              `json:"retryable,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+// storedJob is a job as persisted, with the process running it and when
+// that process last recorded it was alive.
+type storedJob struct {
+	Job
+	Runner    string    `json:"runner"`
+	Heartbeat time.Time `json:"heartbeat"`
+}
+
+// JobHandle is the result of calling an asynchronous command.
+type JobHandle struct {
+	JobID  string    `json:"job_id"`
+	Status JobStatus `json:"status"`
+}
+
+// errJobCancelled is the cause of the context of a job cancelled by the host.
+var errJobCancelled = errors.New("job cancelled")
+
+// WithJobRetention sets how long finished jobs are kept in storage. Expired
+// jobs are deleted when the host reads or lists them. Zero keeps them
+// forever. Defaults to DefaultJobRetention.
+func WithJobRetention(retention time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.jobRetention = retention
+	}
+}
+
+// WithAsync makes the command asynchronous: calling it returns a JobHandle
+// at once, while the handler runs in the background with its own Context.
+// The host follows the job with the JobStatusCommand, JobCancelCommand and
+// JobListCommand commands. The command's own timeouts bound the job, the
+// timeout of the call does not.
+func WithAsync() CommandOption {
+	return func(m *CommandMetadata) {
+		m.Async = true
+	}
+}
+
+// jobRegistry tracks the jobs running in the process.
+type jobRegistry struct {
+	runner    string // identifies the process in persisted jobs
+	retention time.Duration
+	heartbeat time.Duration
+	lease     time.Duration
+
+	mu      sync.Mutex
+	running map[string]*runningJob // by job ID
+}
+
+// newJobRegistry creates an empty job registry keeping finished jobs for retention.
+func newJobRegistry(retention time.Duration) *jobRegistry {
+	return &jobRegistry{
+		runner:    uuid.NewString(),
+		retention: retention,
+		heartbeat: jobHeartbeat,
+		lease:     jobLease,
+		running:   make(map[string]*runningJob),
+	}
+}
+
+// get returns a job running in the process.
+func (r *jobRegistry) get(id string) (*runningJob, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	job, exists := r.running[id]
+	return job, exists
+}
+
+// put registers a running job.
+func (r *jobRegistry) put(job *runningJob) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.running[job.job.ID] = job
+}
+
+// remove unregisters a job once it ended.
+func (r *jobRegistry) remove(id string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.running, id)
+}
+
+// runningJob is a job running in the process, persisted on each change.
+type runningJob struct {
+	key      instanceKey
+	runner   string
+	storage  *stub.StorageClient
+	persist  context.Context // not cancelled with the job
+	cancel   context.CancelCauseFunc
+	interval time.Duration
+
+	mu        sync.Mutex
+	job       Job
+	lastSaved time.Time
+}
+
+// snapshot returns a copy of the job.
+func (j *runningJob) snapshot() Job {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.job
+}
+
+// update changes the job and persists it. Unless force is set, saving is
+// skipped within the progress interval of the previous save.
+func (j *runningJob) update(force bool, change func(job *Job)) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	now := time.Now()
+	change(&j.job)
+	j.job.UpdatedAt = now
+	if !force && now.Sub(j.lastSaved) < j.interval {
+		return nil
+	}
+	return j.save(now)
+}
+
+// save persists the job, recording the process as alive. It must be
+// called with the job locked.
+func (j *runningJob) save(now time.Time) error {
+	j.lastSaved = now
+	return saveJob(j.persist, j.storage, storedJob{Job: j.job, Runner: j.runner, Heartbeat: now})
+}
+
+// beat persists the job every interval, so that other processes sharing
+// the storage do not take it as interrupted, until the returned function
+// is called.
+func (j *runningJob) beat(interval time.Duration) func() {
+	stop := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				j.mu.Lock()
+				_ = j.save(time.Now())
+				j.mu.Unlock()
+			}
+		}
+	}()
+	return func() {
+		close(stop)
+		<-stopped
+	}
+}
+
+// stop cancels the job and records it as cancelled, even if its handler
+// has yet to return.
+func (j *runningJob) stop() {
+	j.cancel(errJobCancelled)
+	_ = j.update(true, func(job *Job) {
+		if !job.Status.Finished() {
+			job.Status = JobCancelled
+			job.Message = errJobCancelled.Error()
+		}
+	})
+}
+
+// jobProgress records the progress of a job and forwards it to the host.
+type jobProgress struct {
+	job  *runningJob
+	next ProgressReporter
+}
+
+// Report implements ProgressReporter.
+func (p jobProgress) Report(percent float64, message string) {
+	percent = min(max(percent, 0), 100)
+	_ = p.job.update(false, func(job *Job) {
+		if !job.Status.Finished() {
+			job.Progress = percent
+			job.Message = message
+		}
+	})
+	p.next.Report(percent, message)
+}
+
+// startJob persists a job of an asynchronous command, runs it in the
+// background and returns its handle.
+func (s *Server) startJob(ctx context.Context, key instanceKey, entry *instanceEntry, executor CommandInstance, cmd CommandMetadata, command string, args []interface{}) *pluginpb.ExecuteCommandResponse {
+	// The job outlives the call, keeping its metadata but not its deadline
+	jobCtx, endJob, ok := s.inflight.begin(context.WithoutCancel(ctx))
+	if !ok {
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, Unavailable("plugin is shutting down"), CodeExecutionError),
+			},
+		}
+	}
+
+	// Keep the tenant's instance from being disabled while the job runs
+	jobCtx, endCall, err := entry.begin(jobCtx)
+	if err != nil {
+		endJob()
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, err, CodeExecutionError),
+			},
+		}
+	}
+
+	// Let the host cancel the job by its request ID as well as its job ID
+	jobCtx, unregister := s.cancels.register(jobCtx, key)
+	done := func() {
+		unregister()
+		endCall()
+		endJob()
+	}
+	jobCtx, cancel := context.WithCancelCause(jobCtx)
+
+	pluginCtx := s.newContext(jobCtx, key)
+	now := time.Now()
+	job := &runningJob{
+		key:      key,
+		runner:   s.jobs.runner,
+		storage:  pluginCtx.Storage,
+		persist:  context.WithoutCancel(pluginCtx),
+		cancel:   cancel,
+		interval: s.progress.interval,
+		job: Job{
+			ID:        uuid.NewString(),
+			Command:   command,
+			RequestID: pluginCtx.Request.RequestID,
+			Status:    JobPending,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+	}
+
+	// Register the job before persisting it, so that it is never seen
+	// unfinished in storage while missing from the registry
+	s.jobs.put(job)
+	if err := job.update(true, func(*Job) {}); err != nil {
+		s.jobs.remove(job.job.ID)
+		cancel(err)
+		done()
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, Unavailable("failed to persist job").WithCause(err), CodeExecutionError),
+			},
+		}
+	}
+
+	go func() {
+		defer done()
+		defer s.jobs.remove(job.job.ID)
+		defer job.beat(s.jobs.heartbeat)()
+		s.runJob(pluginCtx, job, key, executor, cmd, command, args)
+	}()
+
+	return reservedResponse(JobHandle{JobID: job.job.ID, Status: JobPending}, nil)
+}
+
+// runJob runs the handler of a job and records its outcome.
+func (s *Server) runJob(ctx *Context, job *runningJob, key instanceKey, executor CommandInstance, cmd CommandMetadata, command string, args []interface{}) {
+	var execCtx context.Context = ctx
+	if timeout := commandTimeout(0, cmd); timeout > 0 {
+		var cancel context.CancelFunc
+		execCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var result interface{}
+	release, _, err := s.limits.acquire(execCtx, key.tenantID, cmd)
+	if err == nil {
+		_ = job.update(true, func(j *Job) {
+			if !j.Status.Finished() {
+				j.Status = JobRunning
+			}
+		})
+
+		pluginCtx := s.newContext(execCtx, key)
+		pluginCtx.progress = jobProgress{job: job, next: s.progress.reporter(pluginCtx, command)}
+		err = recoverPanic(pluginCtx.localLogger(), "job "+command, func() error {
+			var execErr error
+			result, execErr = executor.ExecuteCommand(pluginCtx, command, args)
+			return execErr
+		})
+		s.panics.observe(err)
+		release()
+	}
+
+	var data []byte
+	if err == nil {
+		var marshalErr error
+		if data, marshalErr = json.Marshal(result); marshalErr != nil {
+			err = NewError(CodeSerializationError, "failed to marshal result: %v", marshalErr)
+		}
+	}
+
+	_ = job.update(true, func(j *Job) {
+		switch {
+		case err == nil:
+			j.Status = JobSucceeded
+			j.Progress = 100
+			j.Result = data
+		case ctx.Err() != nil:
+			// Cancelled by the host, or by shutdown after the drain timeout
+			j.Status = JobCancelled
+			j.Message = context.Cause(ctx).Error()
+		default:
+			jobErr := deadlineError(execCtx, err)
+			pluginErr := toPluginError(jobErr, CodeExecutionError)
+			retryable, details := errorReport(jobErr)
+			j.Status = JobFailed
+			j.Error = &JobError{Code: pluginErr.Code, Message: pluginErr.Message, Retryable: retryable, Details: details}
+		}
+	})
+}
+
+// jobCommand answers a reserved job command.
+func (s *Server) jobCommand(ctx context.Context, key instanceKey, req *pluginpb.ExecuteCommandRequest) *pluginpb.ExecuteCommandResponse {
+	pluginCtx := s.newContext(ctx, key)
+
+	arg := make(map[string]string)
+	if len(req.Args) > 0 {
+		var raw interface{}
+		if err := json.Unmarshal(req.Args[0], &raw); err != nil {
+			return reservedResponse(nil, InvalidArgument("failed to unmarshal argument: %v", err))
+		}
+		switch value := raw.(type) {
+		case string:
+			// A positional argument is the status filter of the job list,
+			// and the job ID of the other commands
+			if req.Command == JobListCommand {
+				arg["status"] = value
+			} else {
+				arg["job_id"] = value
+			}
+		case map[string]interface{}:
+			for name, v := range value {
+				arg[name], _ = v.(string)
+			}
+		}
+	}
+
+	switch req.Command {
+	case JobListCommand:
+		return reservedResponse(s.listJobs(pluginCtx, key, JobStatus(arg["status"])))
+	case JobCancelCommand:
+		if running, exists := s.jobs.get(arg["job_id"]); exists && running.key == key {
+			running.stop()
+		}
+	}
+
+	if arg["job_id"] == "" {
+		return reservedResponse(nil, InvalidArgument("%s requires a job ID", req.Command))
+	}
+	job, err := s.loadJob(pluginCtx, key, arg["job_id"])
+	if err == nil && job == nil {
+		err = NotFound("unknown job: %s", arg["job_id"])
+	}
+	return reservedResponse(job, err)
+}
+
+// loadJob returns a job of the tenant, nil if it does not exist or expired.
+// The live state of jobs running in the process is preferred over their
+// persisted state. Unfinished jobs whose process stopped recording
+// heartbeats for the job lease are recorded as failed, and expired jobs are
+// deleted.
+func (s *Server) loadJob(ctx *Context, key instanceKey, id string) (*Job, error) {
+	if running, exists := s.jobs.get(id); exists && running.key == key {
+		job := running.snapshot()
+		return &job, nil
+	}
+
+	value, err := ctx.Storage.Get(ctx, JobStoragePrefix+id)
+	if err != nil {
+		return nil, Unavailable("failed to load job").WithCause(err)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	stored, err := decodeJob(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case !stored.Status.Finished() && stored.Runner != s.jobs.runner && time.Since(stored.Heartbeat) > s.jobs.lease:
+		// The process running the job ended before it did
+		stored.Status = JobFailed
+		stored.Error = &JobError{Code: string(CodeUnavailable), Message: "job interrupted: the plugin process running it ended"}
+		stored.UpdatedAt = time.Now()
+		if err := saveJob(ctx, ctx.Storage, stored); err != nil {
+			ctx.localLogger().Warn("failed to record interrupted job", "job_id", id, "error", err)
+		}
+	case stored.Status.Finished() && s.jobs.retention > 0 && time.Since(stored.UpdatedAt) > s.jobs.retention:
+		if err := ctx.Storage.Delete(ctx, JobStoragePrefix+id); err != nil {
+			ctx.localLogger().Warn("failed to delete expired job", "job_id", id, "error", err)
+		}
+		return nil, nil
+	}
+	return &stored.Job, nil
+}
+
+// loadJobs returns the jobs of the context's tenant. Jobs that cannot be
+// loaded are skipped.
+func (s *Server) loadJobs(ctx *Context, key instanceKey) ([]*Job, error) {
+	keys, err := ctx.Storage.Keys(ctx, JobStoragePrefix)
+	if err != nil {
+		return nil, Unavailable("failed to list jobs").WithCause(err)
+	}
+
+	jobs := make([]*Job, 0, len(keys))
+	for _, storageKey := range keys {
+		id, ok := strings.CutPrefix(storageKey, JobStoragePrefix)
+		if !ok {
+			continue
+		}
+		job, err := s.loadJob(ctx, key, id)
+		if err != nil {
+			ctx.localLogger().Warn("skipping job that failed to load", "job_id", id, "error", err)
+			continue
+		}
+		if job != nil {
+			jobs = append(jobs, job)
+		}
+	}
+	return jobs, nil
+}
+
+// listJobs returns the jobs of the context's tenant with the given status,
+// or all of them if status is empty, newest first.
+func (s *Server) listJobs(ctx *Context, key instanceKey, status JobStatus) ([]*Job, error) {
+	all, err := s.loadJobs(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*Job, 0, len(all))
+	for _, job := range all {
+		if status == "" || job.Status == status {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs, nil
+}
+
+// saveJob persists job in the tenant's storage.
+func saveJob(ctx context.Context, storage *stub.StorageClient, job storedJob) error {
+	return storage.Set(ctx, JobStoragePrefix+job.ID, job)
+}
+
+// decodeJob converts a job read from storage.
+func decodeJob(value interface{}) (storedJob, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return storedJob{}, Internal("failed to decode job").WithCause(err)
+	}
+	var job storedJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return storedJob{}, Internal("failed to decode job").WithCause(err)
+	}
+	return job, nil
+}
@@ -0,0 +1,326 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// jobsPlugin has the async commands "count", reporting progress and
+// returning 10, "boom", panicking, and "forever", running until cancelled.
+func jobsPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("count", func(ctx *Context) (interface{}, error) {
+		for i := 0; i < 10; i++ {
+			ctx.Progress(float64(i*10), "counting")
+		}
+		return 10, nil
+	}, WithAsync()))
+	must(t, plugin.RegisterCommand("boom", func(ctx *Context) (interface{}, error) {
+		panic("kaboom")
+	}, WithAsync()))
+	must(t, plugin.RegisterCommand("forever", func(ctx *Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, WithAsync()))
+	return plugin
+}
+
+// startJob calls an async command and returns its job ID.
+func (tt testTarget) startJob(t *testing.T, server *Server, command string) string {
+	t.Helper()
+	var handle JobHandle
+	tt.run(t, server, &handle, command)
+	if handle.JobID == "" || handle.Status != JobPending {
+		t.Fatalf("%s returned %+v, want a pending job", command, handle)
+	}
+	return handle.JobID
+}
+
+// awaitJob polls the status of a job until it has ended.
+func (tt testTarget) awaitJob(t *testing.T, server *Server, id string) Job {
+	t.Helper()
+	var job Job
+	waitFor(t, func() bool {
+		tt.run(t, server, &job, JobStatusCommand, id)
+		return job.Status.Finished()
+	})
+	return job
+}
+
+// storeJob persists a job in the storage of the target.
+func (tt testTarget) storeJob(t *testing.T, server *Server, job storedJob) {
+	t.Helper()
+	data, err := json.Marshal(job)
+	must(t, err)
+	tt.storeRaw(t, server, JobStoragePrefix+job.ID, data)
+}
+
+// loadStored reads a job from the storage of the target.
+func (tt testTarget) loadStored(t *testing.T, server *Server, id string) storedJob {
+	t.Helper()
+	resp, err := server.capabilitiesClient.StorageGet(testContext(t), &pluginpb.StorageGetRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+		Key:      JobStoragePrefix + id,
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("StorageGet(%s): %v %v", id, err, resp.Error)
+	}
+	var job storedJob
+	must(t, json.Unmarshal(resp.Value, &job))
+	return job
+}
+
+// storeRaw writes value under key in the storage of the target.
+func (tt testTarget) storeRaw(t *testing.T, server *Server, key string, value []byte) {
+	t.Helper()
+	resp, err := server.capabilitiesClient.StorageSet(testContext(t), &pluginpb.StorageSetRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+		Key:      key,
+		Value:    value,
+	})
+	if err != nil || resp.Error != nil {
+		t.Fatalf("StorageSet(%s): %v %v", key, err, resp.Error)
+	}
+}
+
+// stored checks if key exists in the storage of the target.
+func (tt testTarget) stored(t *testing.T, server *Server, key string) bool {
+	t.Helper()
+	resp, err := server.capabilitiesClient.StorageGet(testContext(t), &pluginpb.StorageGetRequest{
+		TenantId: tt.tenantID.String(),
+		PluginId: tt.pluginID.String(),
+		Key:      key,
+	})
+	must(t, err)
+	return resp.Error == nil
+}
+
+func TestJobSucceeds(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+
+	job := target.awaitJob(t, server, target.startJob(t, server, "count"))
+	if job.Status != JobSucceeded || job.Progress != 100 || string(job.Result) != "10" || job.Command != "count" {
+		t.Fatalf("job = %+v, want succeeded with result 10", job)
+	}
+}
+
+func TestJobFailsOnPanic(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+
+	job := target.awaitJob(t, server, target.startJob(t, server, "boom"))
+	if job.Status != JobFailed || job.Error == nil || job.Error.Code != string(CodePanic) {
+		t.Fatalf("job = %+v, want failed with %s", job, CodePanic)
+	}
+}
+
+func TestJobCancelReturnsCancelledJob(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+	id := target.startJob(t, server, "forever")
+
+	var job Job
+	target.run(t, server, &job, JobCancelCommand, map[string]string{"job_id": id})
+	if job.Status != JobCancelled {
+		t.Fatalf("__job_cancel returned %+v, want it cancelled", job)
+	}
+	if job = target.awaitJob(t, server, id); job.Status != JobCancelled {
+		t.Fatalf("job after its handler returned = %+v, want it cancelled", job)
+	}
+
+	// Cancelling a finished job leaves it as it ended
+	done := target.awaitJob(t, server, target.startJob(t, server, "count"))
+	target.run(t, server, &job, JobCancelCommand, done.ID)
+	if job.Status != JobSucceeded {
+		t.Fatalf("__job_cancel of a finished job returned %+v, want it succeeded", job)
+	}
+}
+
+func TestJobCommandErrors(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+
+	if perr := target.execute(t, server, testContext(t), nil, JobStatusCommand, "missing"); perr.GetCode() != string(CodeNotFound) {
+		t.Errorf("status of an unknown job: error = %v, want %s", perr, CodeNotFound)
+	}
+	if perr := target.execute(t, server, testContext(t), nil, JobStatusCommand); perr.GetCode() != string(CodeInvalidArgument) {
+		t.Errorf("status without a job ID: error = %v, want %s", perr, CodeInvalidArgument)
+	}
+
+	// Jobs of another tenant are not visible
+	id := target.startJob(t, server, "count")
+	target.awaitJob(t, server, id)
+	if perr := newTestTarget().execute(t, server, testContext(t), nil, JobStatusCommand, id); perr.GetCode() != string(CodeNotFound) {
+		t.Errorf("status of another tenant's job: error = %v, want %s", perr, CodeNotFound)
+	}
+}
+
+func TestJobListSkipsUnreadableJobs(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+
+	first := target.awaitJob(t, server, target.startJob(t, server, "count"))
+	second := target.awaitJob(t, server, target.startJob(t, server, "boom"))
+	target.storeRaw(t, server, JobStoragePrefix+"corrupt", []byte(`"not a job"`))
+
+	var jobs []Job
+	target.run(t, server, &jobs, JobListCommand)
+	if len(jobs) != 2 || jobs[0].ID != second.ID || jobs[1].ID != first.ID {
+		t.Fatalf("jobs = %+v, want the two jobs newest first", jobs)
+	}
+
+	target.run(t, server, &jobs, JobListCommand, "failed")
+	if len(jobs) != 1 || jobs[0].ID != second.ID {
+		t.Fatalf("failed jobs = %+v, want the panicking one", jobs)
+	}
+}
+
+func TestInterruptedJobRecordedAsFailed(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+	created := time.Now().Add(-time.Hour)
+	target.storeJob(t, server, storedJob{
+		Job: Job{
+			ID:        "orphan",
+			Command:   "count",
+			Status:    JobRunning,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		Runner:    "crashed-process",
+		Heartbeat: time.Now().Add(-2 * jobLease),
+	})
+
+	var job Job
+	target.run(t, server, &job, JobCancelCommand, "orphan")
+	if job.Status != JobFailed || job.Error == nil || job.Error.Code != string(CodeUnavailable) {
+		t.Fatalf("interrupted job = %+v, want failed with %s", job, CodeUnavailable)
+	}
+
+	// The failure is persisted
+	var jobs []Job
+	target.run(t, server, &jobs, JobListCommand, "running")
+	if len(jobs) != 0 {
+		t.Fatalf("running jobs = %+v, want none", jobs)
+	}
+}
+
+func TestJobOfLiveProcessNotInterrupted(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	target := newTestTarget()
+	created := time.Now().Add(-time.Hour)
+	target.storeJob(t, server, storedJob{
+		Job: Job{
+			ID:        "elsewhere",
+			Command:   "count",
+			Status:    JobRunning,
+			CreatedAt: created,
+			UpdatedAt: created,
+		},
+		Runner:    "other-process",
+		Heartbeat: time.Now(),
+	})
+
+	var job Job
+	target.run(t, server, &job, JobStatusCommand, "elsewhere")
+	if job.Status != JobRunning || job.Error != nil {
+		t.Fatalf("job of another live process = %+v, want it running", job)
+	}
+}
+
+func TestRunningJobRecordsHeartbeats(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t))
+	server.jobs.heartbeat = time.Millisecond
+	target := newTestTarget()
+	id := target.startJob(t, server, "forever")
+	defer target.run(t, server, nil, JobCancelCommand, id)
+
+	first := target.loadStored(t, server, id).Heartbeat
+	waitFor(t, func() bool {
+		return target.loadStored(t, server, id).Heartbeat.After(first)
+	})
+}
+
+func TestJobSucceedsWhenHandlerIgnoresCancellation(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("stubborn", func(ctx *Context) (interface{}, error) {
+		close(started)
+		<-release
+		return "done", nil
+	}, WithAsync()))
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+	id := target.startJob(t, server, "stubborn")
+	<-started
+
+	var job Job
+	target.run(t, server, &job, JobCancelCommand, id)
+	if job.Status != JobCancelled {
+		t.Fatalf("__job_cancel returned %+v, want it cancelled", job)
+	}
+
+	// The handler returning a result despite the cancellation wins
+	close(release)
+	waitFor(t, func() bool {
+		target.run(t, server, &job, JobStatusCommand, id)
+		return job.Status == JobSucceeded
+	})
+	if string(job.Result) != `"done"` {
+		t.Fatalf("job = %+v, want its result", job)
+	}
+}
+
+func TestExpiredJobsDeleted(t *testing.T) {
+	server := newTestServer(t, jobsPlugin(t), WithJobRetention(time.Hour))
+	target := newTestTarget()
+	old := time.Now().Add(-2 * time.Hour)
+	for _, id := range []string{"expired-1", "expired-2"} {
+		target.storeJob(t, server, storedJob{
+			Job:    Job{ID: id, Status: JobSucceeded, CreatedAt: old, UpdatedAt: old},
+			Runner: "old-process",
+		})
+	}
+
+	// Reading an expired job deletes it
+	if perr := target.execute(t, server, testContext(t), nil, JobStatusCommand, "expired-1"); perr.GetCode() != string(CodeNotFound) {
+		t.Fatalf("status of an expired job: error = %v, want %s", perr, CodeNotFound)
+	}
+	if target.stored(t, server, JobStoragePrefix+"expired-1") {
+		t.Fatal("expired job read by the host still stored")
+	}
+
+	// Listing the jobs deletes the expired ones
+	id := target.startJob(t, server, "count")
+	target.awaitJob(t, server, id)
+	var jobs []Job
+	target.run(t, server, &jobs, JobListCommand)
+	if len(jobs) != 1 || jobs[0].ID != id {
+		t.Fatalf("listed jobs = %+v, want only %s", jobs, id)
+	}
+	if target.stored(t, server, JobStoragePrefix+"expired-2") {
+		t.Fatal("expired job listed by the host still stored")
+	}
+	if !target.stored(t, server, JobStoragePrefix+id) {
+		t.Fatal("job within the retention deleted")
+	}
+}
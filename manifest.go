@@ -0,0 +1,208 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// SDKVersion is the version of the SDK recorded in plugin manifests.
+const SDKVersion = "0.1.0"
+
+// ManifestCommand is the reserved command returning the plugin's Manifest.
+const ManifestCommand = "__manifest"
+
+// Capability is a host capability a plugin uses.
+type Capability string
+
+// Capabilities a plugin can declare in its manifest.
+const (
+	CapabilityStorage       Capability = "storage"
+	CapabilityHTTP          Capability = "http"
+	CapabilityQueue         Capability = "queue"
+	CapabilityNotifications Capability = "notifications"
+	CapabilitySecrets       Capability = "secrets"
+	CapabilitySongs         Capability = "songs"
+	CapabilityUsers         Capability = "users"
+)
+
+// knownCapabilities are the capabilities accepted in a manifest.
+var knownCapabilities = map[Capability]bool{
+	CapabilityStorage:       true,
+	CapabilityHTTP:          true,
+	CapabilityQueue:         true,
+	CapabilityNotifications: true,
+	CapabilitySecrets:       true,
+	CapabilitySongs:         true,
+	CapabilityUsers:         true,
+}
+
+// semverPattern matches a semantic version such as "1.4.0" or "2.0.0-beta.1".
+var semverPattern = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)(-[0-9A-Za-z.-]+)?(\+[0-9A-Za-z.-]+)?$`)
+
+// Manifest describes a plugin to the host, which validates it before
+// launching the plugin.
+type Manifest struct {
+	// ID uniquely identifies the plugin, such as "youtube-downloader".
+	ID string `json:"id"`
+
+	// Name is the human-readable name of the plugin.
+	Name string `json:"name"`
+
+	// Version is the semantic version of the plugin.
+	Version string `json:"version"`
+
+	// SDKVersion is the version of the SDK the plugin was built with.
+	// Set by BuildManifest.
+	SDKVersion string `json:"sdk_version"`
+
+	// Description describes what the plugin does.
+	Description string `json:"description,omitempty"`
+
+	// Capabilities are the host capabilities the plugin uses.
+	Capabilities []Capability `json:"capabilities,omitempty"`
+
+	// HTTPDomains are the domains the plugin may fetch through the HTTP capability.
+	HTTPDomains []string `json:"http_domains,omitempty"`
+
+	// ConfigSchema describes the configuration the plugin accepts.
+	ConfigSchema []ParameterMetadata `json:"config_schema,omitempty"`
+
+	// Commands are the commands of the plugin.
+	// Filled from GetCommands() by BuildManifest when left empty.
+	Commands []CommandMetadata `json:"commands"`
+}
+
+// ManifestProvider is implemented by plugins declaring a manifest.
+type ManifestProvider interface {
+	// Manifest returns the plugin's manifest.
+	Manifest() Manifest
+}
+
+// Validate checks that the manifest is complete and consistent.
+func (m Manifest) Validate() error {
+	var errs []error
+	if m.ID == "" {
+		errs = append(errs, fmt.Errorf("id is required"))
+	}
+	if m.Name == "" {
+		errs = append(errs, fmt.Errorf("name is required"))
+	}
+	if !semverPattern.MatchString(m.Version) {
+		errs = append(errs, fmt.Errorf("version %q is not a semantic version", m.Version))
+	}
+
+	usesHTTP := false
+	for _, capability := range m.Capabilities {
+		if !knownCapabilities[capability] {
+			errs = append(errs, fmt.Errorf("unknown capability %q", capability))
+		}
+		usesHTTP = usesHTTP || capability == CapabilityHTTP
+	}
+	if len(m.HTTPDomains) > 0 && !usesHTTP {
+		errs = append(errs, fmt.Errorf("http_domains requires the %q capability", CapabilityHTTP))
+	}
+	for _, domain := range m.HTTPDomains {
+		if domain == "" || strings.Contains(domain, "/") {
+			errs = append(errs, fmt.Errorf("invalid HTTP domain %q", domain))
+		}
+	}
+
+	seen := make(map[string]bool, len(m.Commands))
+	for _, cmd := range m.Commands {
+		if seen[cmd.Name] {
+			errs = append(errs, fmt.Errorf("duplicate command %q", cmd.Name))
+		}
+		seen[cmd.Name] = true
+		if err := cmd.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("command %q: %w", cmd.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// BuildManifest returns the manifest of plugin, with its SDK version and
+// command list filled in, and validates it.
+func BuildManifest(plugin Plugin) (Manifest, error) {
+	provider, ok := plugin.(ManifestProvider)
+	if !ok {
+		return Manifest{}, NewError(CodeNotSupported, "plugin does not declare a manifest")
+	}
+
+	// Specialized plugins expose their commands once registered
+	if err := registerSpecializedCommands(plugin); err != nil {
+		return Manifest{}, Internal("failed to register specialized commands").WithCause(err)
+	}
+
+	manifest := provider.Manifest()
+	manifest.SDKVersion = SDKVersion
+	manifest.Commands = append([]CommandMetadata(nil), manifest.Commands...)
+	if len(manifest.Commands) == 0 {
+		if lister, ok := plugin.(interface{ GetCommands() []CommandMetadata }); ok {
+			manifest.Commands = lister.GetCommands()
+		}
+	}
+	sort.Slice(manifest.Commands, func(i, j int) bool {
+		return manifest.Commands[i].Name < manifest.Commands[j].Name
+	})
+	if manifest.Commands == nil {
+		manifest.Commands = []CommandMetadata{}
+	}
+
+	if err := manifest.Validate(); err != nil {
+		return Manifest{}, InvalidArgument("invalid manifest").WithCause(err)
+	}
+	return manifest, nil
+}
+
+// WriteManifest writes the manifest of plugin to path as indented JSON.
+func WriteManifest(plugin Plugin, path string) error {
+	manifest, err := BuildManifest(plugin)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal manifest: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// manifestPath returns the path given with --manifest in args, if any.
+// Both "--manifest path" and "--manifest=path" are accepted; without a
+// path, or when the next argument is a flag, it is "manifest.json".
+func manifestPath(args []string) (string, bool) {
+	for i, arg := range args {
+		switch {
+		case arg == "--manifest" || arg == "-manifest":
+			if i+1 < len(args) && !strings.HasPrefix(args[i+1], "-") {
+				return args[i+1], true
+			}
+			return "manifest.json", true
+		case strings.HasPrefix(arg, "--manifest="):
+			return strings.TrimPrefix(arg, "--manifest="), true
+		case strings.HasPrefix(arg, "-manifest="):
+			return strings.TrimPrefix(arg, "-manifest="), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// manifestPlugin is a BasePlugin declaring a manifest.
+type manifestPlugin struct {
+	*BasePlugin
+	manifest Manifest
+}
+
+func (p manifestPlugin) Manifest() Manifest {
+	return p.manifest
+}
+
+// newManifestPlugin returns a plugin declaring a valid manifest, with the
+// commands "search" and "download" registered.
+func newManifestPlugin(t *testing.T) manifestPlugin {
+	t.Helper()
+	plugin := manifestPlugin{
+		BasePlugin: NewBasePlugin(),
+		manifest: Manifest{
+			ID:           "song-finder",
+			Name:         "Song Finder",
+			Version:      "1.2.0",
+			Capabilities: []Capability{CapabilityHTTP, CapabilityStorage},
+			HTTPDomains:  []string{"example.com"},
+		},
+	}
+	noop := func(ctx *Context) (interface{}, error) { return nil, nil }
+	must(t, plugin.RegisterCommand("search", noop, WithDescription("Searches songs")))
+	must(t, plugin.RegisterCommand("download", noop))
+	return plugin
+}
+
+func TestBuildManifestFillsCommandsAndSDKVersion(t *testing.T) {
+	manifest, err := BuildManifest(newManifestPlugin(t))
+	must(t, err)
+
+	if manifest.SDKVersion != SDKVersion {
+		t.Errorf("SDK version = %q, want %q", manifest.SDKVersion, SDKVersion)
+	}
+	if len(manifest.Commands) != 2 || manifest.Commands[0].Name != "download" || manifest.Commands[1].Name != "search" {
+		t.Fatalf("commands = %+v, want download and search sorted", manifest.Commands)
+	}
+	if manifest.Commands[1].Description != "Searches songs" {
+		t.Errorf("search description = %q, want the registered one", manifest.Commands[1].Description)
+	}
+}
+
+func TestBuildManifestRequiresProvider(t *testing.T) {
+	if _, err := BuildManifest(NewBasePlugin()); errorCode(err) != CodeNotSupported {
+		t.Fatalf("error = %v, want %s", err, CodeNotSupported)
+	}
+}
+
+func TestManifestValidate(t *testing.T) {
+	valid := newManifestPlugin(t).manifest
+	must(t, valid.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(m *Manifest)
+		want   string
+	}{
+		{"missing ID", func(m *Manifest) { m.ID = "" }, "id is required"},
+		{"missing name", func(m *Manifest) { m.Name = "" }, "name is required"},
+		{"invalid version", func(m *Manifest) { m.Version = "1.0" }, "not a semantic version"},
+		{"unknown capability", func(m *Manifest) { m.Capabilities = append(m.Capabilities, "teleport") }, `unknown capability "teleport"`},
+		{"domains without HTTP", func(m *Manifest) { m.Capabilities = []Capability{CapabilityStorage} }, "requires the \"http\" capability"},
+		{"invalid domain", func(m *Manifest) { m.HTTPDomains = []string{"example.com/path"} }, "invalid HTTP domain"},
+		{"duplicate command", func(m *Manifest) {
+			m.Commands = []CommandMetadata{{Name: "search"}, {Name: "search"}}
+		}, `duplicate command "search"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := valid
+			m.Capabilities = append([]Capability(nil), valid.Capabilities...)
+			tt.modify(&m)
+			if err := m.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Validate() = %v, want an error containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	must(t, WriteManifest(newManifestPlugin(t), path))
+
+	data, err := os.ReadFile(path)
+	must(t, err)
+	var manifest Manifest
+	must(t, json.Unmarshal(data, &manifest))
+	if manifest.ID != "song-finder" || manifest.SDKVersion != SDKVersion || len(manifest.Commands) != 2 {
+		t.Fatalf("written manifest = %+v, want the built one", manifest)
+	}
+
+	invalid := newManifestPlugin(t)
+	invalid.manifest.Version = "latest"
+	path = filepath.Join(t.TempDir(), "invalid.json")
+	if err := WriteManifest(invalid, path); errorCode(err) != CodeInvalidArgument {
+		t.Fatalf("error = %v, want %s", err, CodeInvalidArgument)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("invalid manifest written: %v", err)
+	}
+}
+
+func TestManifestPath(t *testing.T) {
+	tests := []struct {
+		args   []string
+		want   string
+		wantOK bool
+	}{
+		{nil, "", false},
+		{[]string{"--verbose"}, "", false},
+		{[]string{"--manifest", "out.json"}, "out.json", true},
+		{[]string{"--manifest=out.json"}, "out.json", true},
+		{[]string{"--manifest"}, "manifest.json", true},
+		{[]string{"--manifest", "--dev"}, "manifest.json", true},
+		{[]string{"-manifest", "-v"}, "manifest.json", true},
+	}
+	for _, tt := range tests {
+		got, ok := manifestPath(tt.args)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("manifestPath(%q) = %q, %v, want %q, %v", tt.args, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestManifestCommand(t *testing.T) {
+	server := newTestServer(t, newManifestPlugin(t))
+
+	var manifest Manifest
+	newTestTarget().run(t, server, &manifest, ManifestCommand)
+	if manifest.ID != "song-finder" || len(manifest.Commands) != 2 {
+		t.Fatalf("__manifest = %+v, want the plugin's manifest", manifest)
+	}
+}
+
+func TestManifestCommandOptOut(t *testing.T) {
+	plugin := newManifestPlugin(t)
+	plugin.DisableIntrospection()
+	server := newTestServer(t, plugin)
+
+	if perr := newTestTarget().execute(t, server, testContext(t), nil, ManifestCommand); perr.GetCode() != string(CodeNotFound) {
+		t.Fatalf("__manifest with introspection disabled: error = %v, want %s", perr, CodeNotFound)
+	}
+}
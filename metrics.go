@@ -0,0 +1,377 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// MetricsCommand is the reserved command returning a MetricsSnapshot.
+const MetricsCommand = "__metrics"
+
+// Metric names kept by the SDK.
+const (
+	MetricCommandsTotal                 = "wabisaby_plugin_commands_total"
+	MetricCommandDurationSeconds        = "wabisaby_plugin_command_duration_seconds"
+	MetricCommandCapabilityCallsTotal   = "wabisaby_plugin_command_capability_calls_total"
+	MetricCapabilityCallsTotal          = "wabisaby_plugin_capability_calls_total"
+	MetricCapabilityCallDurationSeconds = "wabisaby_plugin_capability_call_duration_seconds"
+	MetricCommandsInFlight              = "wabisaby_plugin_commands_in_flight"
+	MetricPanicsTotal                   = "wabisaby_plugin_panics_total"
+)
+
+// outcomeOK is the code and outcome label of successful calls.
+const outcomeOK = "OK"
+
+// unknownLabel replaces command and tenant labels the plugin does not know,
+// so that arbitrary values sent by the host do not create new series.
+const unknownLabel = "unknown"
+
+// DefaultMetricsExportInterval is how often metrics are exported when
+// WithMetricsExporter is given no interval.
+const DefaultMetricsExportInterval = time.Minute
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the latency histograms.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// MetricsSnapshot is a point-in-time copy of the SDK's metrics.
+type MetricsSnapshot struct {
+	Counters   []CounterSample   `json:"counters"`
+	Gauges     []GaugeSample     `json:"gauges"`
+	Histograms []HistogramSample `json:"histograms"`
+}
+
+// CounterSample is the value of a counter.
+type CounterSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  uint64            `json:"value"`
+}
+
+// GaugeSample is the value of a gauge.
+type GaugeSample struct {
+	Name   string            `json:"name"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Value  int64             `json:"value"`
+}
+
+// HistogramSample is the state of a histogram. Bucket counts are
+// cumulative, as in Prometheus.
+type HistogramSample struct {
+	Name    string            `json:"name"`
+	Labels  map[string]string `json:"labels,omitempty"`
+	Buckets []BucketSample    `json:"buckets"`
+	Count   uint64            `json:"count"`
+	Sum     float64           `json:"sum"`
+}
+
+// BucketSample is the number of observations less than or equal to UpperBound.
+type BucketSample struct {
+	UpperBound float64 `json:"upper_bound"`
+	Count      uint64  `json:"count"`
+}
+
+// MetricsExporter receives periodic snapshots of the SDK's metrics, for
+// example to push them to a metrics backend.
+type MetricsExporter interface {
+	ExportMetrics(ctx context.Context, snapshot MetricsSnapshot) error
+}
+
+// MetricsExporterFunc adapts a function to a MetricsExporter.
+type MetricsExporterFunc func(ctx context.Context, snapshot MetricsSnapshot) error
+
+// ExportMetrics implements MetricsExporter.
+func (f MetricsExporterFunc) ExportMetrics(ctx context.Context, snapshot MetricsSnapshot) error {
+	return f(ctx, snapshot)
+}
+
+// WithMetricsExporter exports the SDK's metrics to exporter every interval,
+// and once more when the server is closed. A zero interval defaults to
+// DefaultMetricsExportInterval.
+func WithMetricsExporter(exporter MetricsExporter, interval time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.metricsExporter = exporter
+		c.metricsInterval = interval
+	}
+}
+
+// WithMetricsTenantLabel adds a tenant label to command and capability
+// metrics. Every tenant adds its own series, so only enable it when the
+// number of tenants is bounded.
+func WithMetricsTenantLabel() ServeOption {
+	return func(c *serveConfig) {
+		c.metricsTenantLabel = true
+	}
+}
+
+// metricsRegistry keeps the SDK's counters, gauges and histograms.
+type metricsRegistry struct {
+	mu          sync.Mutex
+	tenantLabel bool
+	counters    map[string]*CounterSample
+	gauges      map[string]*GaugeSample
+	histograms  map[string]*histogram
+}
+
+// histogram accumulates observations into buckets.
+type histogram struct {
+	name   string
+	labels map[string]string
+	bounds []float64
+	counts []uint64 // per bucket, not cumulative; the last one counts +Inf
+	count  uint64
+	sum    float64
+}
+
+// newMetricsRegistry creates an empty metrics registry.
+func newMetricsRegistry(tenantLabel bool) *metricsRegistry {
+	return &metricsRegistry{
+		tenantLabel: tenantLabel,
+		counters:    make(map[string]*CounterSample),
+		gauges:      make(map[string]*GaugeSample),
+		histograms:  make(map[string]*histogram),
+	}
+}
+
+// addCounter adds delta to a counter.
+func (m *metricsRegistry) addCounter(name string, labels map[string]string, delta uint64) {
+	key := seriesKey(name, labels)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	c, ok := m.counters[key]
+	if !ok {
+		c = &CounterSample{Name: name, Labels: labels}
+		m.counters[key] = c
+	}
+	c.Value += delta
+}
+
+// addGauge adds delta to a gauge.
+func (m *metricsRegistry) addGauge(name string, labels map[string]string, delta int64) {
+	key := seriesKey(name, labels)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	g, ok := m.gauges[key]
+	if !ok {
+		g = &GaugeSample{Name: name, Labels: labels}
+		m.gauges[key] = g
+	}
+	g.Value += delta
+}
+
+// observe adds an observation to a histogram with the default latency buckets.
+func (m *metricsRegistry) observe(name string, labels map[string]string, value float64) {
+	key := seriesKey(name, labels)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	h, ok := m.histograms[key]
+	if !ok {
+		h = &histogram{
+			name:   name,
+			labels: labels,
+			bounds: DefaultLatencyBuckets,
+			counts: make([]uint64, len(DefaultLatencyBuckets)+1),
+		}
+		m.histograms[key] = h
+	}
+	h.counts[sort.SearchFloat64s(h.bounds, value)]++
+	h.count++
+	h.sum += value
+}
+
+// snapshot returns a copy of all metrics, sorted by name and labels.
+func (m *metricsRegistry) snapshot() MetricsSnapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	snapshot := MetricsSnapshot{
+		Counters:   make([]CounterSample, 0, len(m.counters)),
+		Gauges:     make([]GaugeSample, 0, len(m.gauges)),
+		Histograms: make([]HistogramSample, 0, len(m.histograms)),
+	}
+	for _, key := range sortedKeys(m.counters) {
+		snapshot.Counters = append(snapshot.Counters, *m.counters[key])
+	}
+	for _, key := range sortedKeys(m.gauges) {
+		snapshot.Gauges = append(snapshot.Gauges, *m.gauges[key])
+	}
+	for _, key := range sortedKeys(m.histograms) {
+		h := m.histograms[key]
+		sample := HistogramSample{
+			Name:    h.name,
+			Labels:  h.labels,
+			Buckets: make([]BucketSample, len(h.bounds)),
+			Count:   h.count,
+			Sum:     h.sum,
+		}
+		var cumulative uint64
+		for i, bound := range h.bounds {
+			cumulative += h.counts[i]
+			sample.Buckets[i] = BucketSample{UpperBound: bound, Count: cumulative}
+		}
+		snapshot.Histograms = append(snapshot.Histograms, sample)
+	}
+	return snapshot
+}
+
+// labels returns the labels of a call, adding the tenant if allowed.
+func (m *metricsRegistry) labels(tenantID string, keysAndValues ...string) map[string]string {
+	labels := make(map[string]string, len(keysAndValues)/2+1)
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		labels[keysAndValues[i]] = keysAndValues[i+1]
+	}
+	if m.tenantLabel && tenantID != "" {
+		labels["tenant"] = tenantID
+	}
+	return labels
+}
+
+// commandStarted records a command starting and returns the function
+// recording its end.
+func (m *metricsRegistry) commandStarted() func(scope *commandScope, code string, duration time.Duration) {
+	m.addGauge(MetricCommandsInFlight, nil, 1)
+	return func(scope *commandScope, code string, duration time.Duration) {
+		m.addGauge(MetricCommandsInFlight, nil, -1)
+		m.addCounter(MetricCommandsTotal, m.labels(scope.tenantID, "command", scope.command, "code", code), 1)
+		m.observe(MetricCommandDurationSeconds, m.labels(scope.tenantID, "command", scope.command), duration.Seconds())
+	}
+}
+
+// panicked records a recovered panic.
+func (m *metricsRegistry) panicked() {
+	m.addCounter(MetricPanicsTotal, nil, 1)
+}
+
+// unaryClientInterceptor returns a client interceptor counting capability
+// calls by method and outcome, and by the command making them.
+func (m *metricsRegistry) unaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		_, name := splitMethod(method)
+		scope, _ := ctx.Value(commandScopeKey{}).(*commandScope)
+
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		duration := time.Since(start)
+
+		outcome := outcomeOK
+		if err != nil {
+			outcome = status.Code(err).String()
+		} else if resp, ok := reply.(interface{ GetError() *pluginpb.PluginError }); ok && resp.GetError() != nil {
+			outcome = resp.GetError().Code
+		}
+
+		var tenantID string
+		if scope != nil {
+			tenantID = scope.tenantID
+			m.addCounter(MetricCommandCapabilityCallsTotal, m.labels(tenantID, "command", scope.command, "method", name), 1)
+		}
+		m.addCounter(MetricCapabilityCallsTotal, m.labels(tenantID, "method", name, "outcome", outcome), 1)
+		m.observe(MetricCapabilityCallDurationSeconds, m.labels(tenantID, "method", name), duration.Seconds())
+		return err
+	}
+}
+
+// export exports a snapshot every interval until stop is closed, then
+// exports a last one.
+func (m *metricsRegistry) export(exporter MetricsExporter, interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	send := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		defer cancel()
+		_ = exporter.ExportMetrics(ctx, m.snapshot())
+	}
+
+	for {
+		select {
+		case <-ticker.C:
+			send()
+		case <-stop:
+			send()
+			return
+		}
+	}
+}
+
+// commandScopeKey is the context key of the command a call belongs to.
+type commandScopeKey struct{}
+
+// commandScope identifies the command execution making capability calls.
+type commandScope struct {
+	command  string
+	tenantID string
+}
+
+// newCommandScope creates the scope of a call. The command stays unknown
+// until the executor is found to handle it, see knownCommand.
+func newCommandScope(tenantID string) *commandScope {
+	if _, err := uuid.Parse(tenantID); err != nil {
+		tenantID = unknownLabel
+	}
+	return &commandScope{command: unknownLabel, tenantID: tenantID}
+}
+
+// knownCommand checks if executor handles command, so that it can label
+// metrics. The commands of executors not describing them are trusted.
+func knownCommand(executor interface{}, command string) bool {
+	switch command {
+	case CommandsCommand, DescribeCommand, PingCommand:
+		return true
+	}
+	describer, ok := executor.(commandDescriber)
+	if !ok {
+		return true
+	}
+	_, registered := describer.GetCommand(command)
+	return registered
+}
+
+// seriesKey identifies a series by its name and labels.
+func seriesKey(name string, labels map[string]string) string {
+	var b strings.Builder
+	b.WriteString(name)
+	for _, key := range sortedKeys(labels) {
+		b.WriteByte(0)
+		b.WriteString(key)
+		b.WriteByte('=')
+		b.WriteString(labels[key])
+	}
+	return b.String()
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,171 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// histogramCount returns the number of observations of the histogram with
+// the given name and labels.
+func histogramCount(snapshot MetricsSnapshot, name string, labels map[string]string) uint64 {
+	var count uint64
+	for _, sample := range snapshot.Histograms {
+		if sample.Name == name && hasLabels(sample.Labels, labels) {
+			count += sample.Count
+		}
+	}
+	return count
+}
+
+// metricsPlugin has an "ok" command and a "fail" command failing with NOT_FOUND.
+func metricsPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("ok", func(ctx *Context) (interface{}, error) {
+		return nil, nil
+	}))
+	must(t, plugin.RegisterCommand("fail", func(ctx *Context) (interface{}, error) {
+		return nil, NotFound("song not found")
+	}))
+	return plugin
+}
+
+func TestMetricsCountCommandsByCode(t *testing.T) {
+	server := newTestServer(t, metricsPlugin(t))
+	target := newTestTarget()
+
+	target.run(t, server, nil, "ok")
+	target.run(t, server, nil, "ok")
+	target.execute(t, server, testContext(t), nil, "fail")
+
+	snapshot := server.Metrics()
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": "ok", "code": outcomeOK}); got != 2 {
+		t.Errorf("ok commands = %d, want 2", got)
+	}
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": "fail", "code": string(CodeNotFound)}); got != 1 {
+		t.Errorf("failed commands = %d, want 1", got)
+	}
+	if got := histogramCount(snapshot, MetricCommandDurationSeconds, map[string]string{"command": "ok"}); got != 2 {
+		t.Errorf("ok durations = %d, want 2", got)
+	}
+	for _, gauge := range snapshot.Gauges {
+		if gauge.Name == MetricCommandsInFlight && gauge.Value != 0 {
+			t.Errorf("commands in flight = %d after all commands ended, want 0", gauge.Value)
+		}
+	}
+}
+
+func TestMetricsLabelUnregisteredCommandsUnknown(t *testing.T) {
+	server := newTestServer(t, metricsPlugin(t))
+	target := newTestTarget()
+
+	for _, command := range []string{"random-1", "random-2", "random-3"} {
+		target.execute(t, server, testContext(t), nil, command)
+	}
+
+	snapshot := server.Metrics()
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": unknownLabel}); got != 3 {
+		t.Fatalf("unknown commands = %d, want 3", got)
+	}
+	for _, sample := range snapshot.Counters {
+		if command := sample.Labels["command"]; command != "" && command != unknownLabel {
+			t.Fatalf("series %s labelled with unregistered command %q", sample.Name, command)
+		}
+	}
+}
+
+func TestMetricsTenantLabel(t *testing.T) {
+	server := newTestServer(t, metricsPlugin(t), WithMetricsTenantLabel())
+	target := newTestTarget()
+
+	target.run(t, server, nil, "ok")
+	req := target.request(t, "ok")
+	req.TenantId = "not-a-tenant"
+	_, err := server.ExecuteCommand(testContext(t), req)
+	must(t, err)
+
+	snapshot := server.Metrics()
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"tenant": target.tenantID.String()}); got != 1 {
+		t.Errorf("commands of the tenant = %d, want 1", got)
+	}
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"tenant": unknownLabel}); got != 1 {
+		t.Errorf("commands of an invalid tenant = %d, want 1 labelled unknown", got)
+	}
+}
+
+func TestMetricsCountCapabilityCalls(t *testing.T) {
+	metrics := newMetricsRegistry(false)
+	interceptor := metrics.unaryClientInterceptor()
+	ctx := context.WithValue(context.Background(), commandScopeKey{}, &commandScope{command: "download"})
+	invoke := func(err error) grpc.UnaryInvoker {
+		return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			return err
+		}
+	}
+
+	must(t, interceptor(ctx, "/wabisaby.plugin.PluginCapabilitiesService/StorageGet", nil, nil, nil, invoke(nil)))
+	_ = interceptor(ctx, "/wabisaby.plugin.PluginCapabilitiesService/StorageGet", nil, nil, nil, invoke(status.Error(codes.Unavailable, "down")))
+
+	snapshot := metrics.snapshot()
+	if got := counterValue(snapshot, MetricCommandCapabilityCallsTotal, map[string]string{"command": "download", "method": "StorageGet"}); got != 2 {
+		t.Errorf("capability calls of the command = %d, want 2", got)
+	}
+	if got := counterValue(snapshot, MetricCapabilityCallsTotal, map[string]string{"method": "StorageGet", "outcome": outcomeOK}); got != 1 {
+		t.Errorf("successful capability calls = %d, want 1", got)
+	}
+	if got := counterValue(snapshot, MetricCapabilityCallsTotal, map[string]string{"method": "StorageGet", "outcome": codes.Unavailable.String()}); got != 1 {
+		t.Errorf("failed capability calls = %d, want 1", got)
+	}
+}
+
+func TestMetricsCommandReturnsSnapshot(t *testing.T) {
+	server := newTestServer(t, metricsPlugin(t))
+	target := newTestTarget()
+	target.run(t, server, nil, "ok")
+
+	var snapshot MetricsSnapshot
+	target.run(t, server, &snapshot, MetricsCommand)
+	if got := counterValue(snapshot, MetricCommandsTotal, map[string]string{"command": "ok"}); got != 1 {
+		t.Fatalf("ok commands in the __metrics snapshot = %d, want 1", got)
+	}
+}
+
+func TestMetricsExportedOnClose(t *testing.T) {
+	var mu sync.Mutex
+	var exported []MetricsSnapshot
+	exporter := MetricsExporterFunc(func(ctx context.Context, snapshot MetricsSnapshot) error {
+		mu.Lock()
+		defer mu.Unlock()
+		exported = append(exported, snapshot)
+		return nil
+	})
+	server := newTestServer(t, metricsPlugin(t), WithMetricsExporter(exporter, time.Hour))
+	newTestTarget().run(t, server, nil, "ok")
+
+	must(t, server.Close())
+	mu.Lock()
+	defer mu.Unlock()
+	if len(exported) != 1 {
+		t.Fatalf("exported %d snapshots on close, want 1", len(exported))
+	}
+	if got := counterValue(exported[0], MetricCommandsTotal, map[string]string{"command": "ok"}); got != 1 {
+		t.Fatalf("ok commands in the exported snapshot = %d, want 1", got)
+	}
+}
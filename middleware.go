@@ -0,0 +1,108 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"time"
+)
+
+// Invocation describes a single command invocation passed through middleware.
+type Invocation struct {
+	// Command is the name of the invoked command.
+	Command string
+
+	// Metadata is the metadata the command was registered with.
+	Metadata CommandMetadata
+
+	// RawArgs are the arguments as received from the host.
+	RawArgs []interface{}
+
+	// Args are the decoded arguments passed to the handler, a pointer to the
+	// handler's argument type, or nil if the handler takes no arguments.
+	// Middleware may modify or replace them before calling next.
+	Args interface{}
+}
+
+// InvokeFunc invokes a command and returns its result.
+type InvokeFunc func(ctx *Context, inv *Invocation) (interface{}, error)
+
+// Middleware wraps a command invocation.
+// A middleware calls next to continue the chain, and may inspect or change
+// the invocation before the call and the result or error after it.
+// Returning without calling next short-circuits the command.
+type Middleware func(next InvokeFunc) InvokeFunc
+
+// WithMiddleware adds middleware that only wraps this command.
+// Command middleware runs inside the router's middleware, in the order given.
+func WithMiddleware(mw ...Middleware) CommandOption {
+	return func(m *CommandMetadata) {
+		m.middleware = append(m.middleware, mw...)
+	}
+}
+
+// chainMiddleware wraps final with mw, so that mw[0] runs first.
+func chainMiddleware(final InvokeFunc, mw ...Middleware) InvokeFunc {
+	for i := len(mw) - 1; i >= 0; i-- {
+		final = mw[i](final)
+	}
+	return final
+}
+
+// LoggingMiddleware returns middleware logging every command invocation
+// with its duration through the context logger. Failed invocations are
+// logged as warnings.
+func LoggingMiddleware() Middleware {
+	return func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			start := time.Now()
+			result, err := next(ctx, inv)
+			if ctx != nil && ctx.Logger != nil {
+				durationMs := time.Since(start).Milliseconds()
+				if err != nil {
+					ctx.Logger.Warn("command failed", "command", inv.Command, "duration_ms", durationMs, "error", err)
+				} else {
+					ctx.Logger.Info("command executed", "command", inv.Command, "duration_ms", durationMs)
+				}
+			}
+			return result, err
+		}
+	}
+}
+
+// LatencyMiddleware returns middleware passing the duration and error of
+// every command invocation to record, for example to feed a histogram.
+func LatencyMiddleware(record func(command string, duration time.Duration, err error)) Middleware {
+	return func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			start := time.Now()
+			result, err := next(ctx, inv)
+			record(inv.Command, time.Since(start), err)
+			return result, err
+		}
+	}
+}
+
+// RecoveryMiddleware returns middleware converting a panic in the rest of
+// the chain into a PANIC error. Handlers are always recovered by the router;
+// this middleware also covers middleware registered after it.
+func RecoveryMiddleware() Middleware {
+	return func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (result interface{}, err error) {
+			err = recoverPanic(ctx.localLogger(), "command "+inv.Command, func() error {
+				var callErr error
+				result, callErr = next(ctx, inv)
+				return callErr
+			})
+			return result, err
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type searchArgs struct {
+	Query string `json:"query"`
+}
+
+// orderMiddleware appends name to *order when it runs.
+func orderMiddleware(name string, order *[]string) Middleware {
+	return func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			*order = append(*order, name)
+			return next(ctx, inv)
+		}
+	}
+}
+
+func echoQuery(ctx *Context, args *searchArgs) (interface{}, error) {
+	return args.Query, nil
+}
+
+func TestMiddlewareRunsRouterThenCommandMiddleware(t *testing.T) {
+	var order []string
+	router := NewCommandRouter()
+	router.Use(orderMiddleware("router-1", &order), orderMiddleware("router-2", &order))
+	must(t, router.Register("search", echoQuery, WithMiddleware(orderMiddleware("command", &order))))
+
+	if _, err := router.Route(&Context{}, "search", nil); err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if got := strings.Join(order, ","); got != "router-1,router-2,command" {
+		t.Fatalf("middleware ran as %s, want router-1,router-2,command", got)
+	}
+}
+
+func TestMiddlewareSeesAndChangesInvocation(t *testing.T) {
+	var seen Invocation
+	sanitize := func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			seen = *inv
+			inv.Args.(*searchArgs).Query = strings.TrimSpace(inv.Args.(*searchArgs).Query)
+			result, err := next(ctx, inv)
+			return "result:" + result.(string), err
+		}
+	}
+	router := NewCommandRouter()
+	must(t, router.Register("search", echoQuery, WithDescription("Search songs"), WithMiddleware(sanitize)))
+
+	raw := []interface{}{map[string]interface{}{"query": "  abba  "}}
+	result, err := router.Route(&Context{}, "search", raw)
+	if err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if result != "result:abba" {
+		t.Fatalf("result = %v, want the sanitized and wrapped query", result)
+	}
+	if seen.Command != "search" || seen.Metadata.Description != "Search songs" || len(seen.RawArgs) != 1 {
+		t.Fatalf("invocation = %+v, want the command, metadata and raw args", seen)
+	}
+}
+
+func TestMiddlewareShortCircuits(t *testing.T) {
+	called := false
+	deny := func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			return nil, PermissionDenied("not allowed")
+		}
+	}
+	router := NewCommandRouter()
+	router.Use(deny)
+	must(t, router.Register("search", func(ctx *Context, args *searchArgs) (interface{}, error) {
+		called = true
+		return nil, nil
+	}))
+
+	_, err := router.Route(&Context{}, "search", nil)
+	var sdkErr *Error
+	if !errors.As(err, &sdkErr) || sdkErr.Code != CodePermissionDenied {
+		t.Fatalf("error = %v, want %s", err, CodePermissionDenied)
+	}
+	if called {
+		t.Fatal("handler ran after the middleware short-circuited")
+	}
+}
+
+func TestRecoveryMiddlewareCoversLaterMiddleware(t *testing.T) {
+	explode := func(next InvokeFunc) InvokeFunc {
+		return func(ctx *Context, inv *Invocation) (interface{}, error) {
+			panic("middleware bug")
+		}
+	}
+	router := NewCommandRouter()
+	router.Use(RecoveryMiddleware(), explode)
+	must(t, router.Register("search", echoQuery))
+
+	_, err := router.Route(&Context{}, "search", nil)
+	if !isPanic(err) {
+		t.Fatalf("error = %v, want a recovered panic", err)
+	}
+}
+
+func TestLatencyMiddlewareRecordsInvocations(t *testing.T) {
+	var recorded []string
+	router := NewCommandRouter()
+	router.Use(LatencyMiddleware(func(command string, duration time.Duration, err error) {
+		recorded = append(recorded, command)
+		if duration < 0 || err != nil {
+			t.Errorf("recorded duration %v and error %v", duration, err)
+		}
+	}))
+	must(t, router.Register("search", echoQuery))
+
+	if _, err := router.Route(&Context{}, "search", nil); err != nil {
+		t.Fatalf("Route: %v", err)
+	}
+	if len(recorded) != 1 || recorded[0] != "search" {
+		t.Fatalf("recorded = %v, want [search]", recorded)
+	}
+}
+
+func TestBasePluginMiddlewareWrapsExecutedCommands(t *testing.T) {
+	var order []string
+	plugin := NewBasePlugin()
+	plugin.Use(LoggingMiddleware(), orderMiddleware("plugin", &order))
+	must(t, plugin.RegisterCommand("search", echoQuery))
+	server := newTestServer(t, plugin)
+
+	var result string
+	newTestTarget().run(t, server, &result, "search", searchArgs{Query: "abba"})
+	if result != "abba" || len(order) != 1 {
+		t.Fatalf("result = %q, middleware calls = %v", result, order)
+	}
+}
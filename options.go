@@ -0,0 +1,257 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	hashicorp_plugin "github.com/hashicorp/go-plugin"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/keepalive"
+)
+
+// ServeOption is a functional option for configuring Serve and NewServer.
+type ServeOption func(*serveConfig)
+
+// serveConfig holds the configuration built from ServeOptions.
+type serveConfig struct {
+	capabilitiesAddr   string
+	security           capabilitiesSecurity
+	dialOptions        []grpc.DialOption
+	serverOptions      []grpc.ServerOption
+	unaryInterceptors  []grpc.UnaryServerInterceptor
+	streamInterceptors []grpc.StreamServerInterceptor
+	maxRecvMsgSize     int
+	maxSendMsgSize     int
+	logger             hclog.Logger
+	handshake          hashicorp_plugin.HandshakeConfig
+
+	protocols map[int]ProtocolHandler
+
+	retryPolicies map[string]RetryPolicy
+	keepalive     keepalive.ClientParameters
+	connectParams grpc.ConnectParams
+
+	panicPolicy    PanicPolicy
+	drainTimeout   time.Duration
+	healthCacheTTL time.Duration
+
+	tenantConcurrency int
+	tenantQueue       int
+
+	tracerProvider trace.TracerProvider
+	propagator     propagation.TextMapPropagator
+
+	metrics            *metricsRegistry
+	metricsExporter    MetricsExporter
+	metricsInterval    time.Duration
+	metricsTenantLabel bool
+
+	progressInterval time.Duration
+	jobRetention     time.Duration
+}
+
+// newServeConfig creates a configuration with defaults applied, then opts.
+// Returns an error if the environment holds an invalid setting.
+func newServeConfig(opts ...ServeOption) (*serveConfig, error) {
+	security, err := securityFromEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &serveConfig{
+		capabilitiesAddr: os.Getenv("WABISABY_CAPABILITIES_ADDR"),
+		security:         security,
+		handshake:        HandshakeConfig(),
+		protocols:        map[int]ProtocolHandler{ProtocolVersion: registerCurrentProtocol},
+		retryPolicies:    defaultRetryPolicies(),
+		keepalive:        DefaultKeepaliveParams(),
+		connectParams:    DefaultConnectParams(),
+		panicPolicy:      DefaultPanicPolicy(),
+		drainTimeout:     DefaultDrainTimeout,
+		healthCacheTTL:   DefaultHealthCacheTTL,
+		progressInterval: DefaultProgressInterval,
+		jobRetention:     DefaultJobRetention,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	if cfg.logger == nil {
+		cfg.logger = hclog.New(&hclog.LoggerOptions{
+			Level:      hclog.Info,
+			Output:     os.Stderr,
+			JSONFormat: true,
+		})
+	}
+	if cfg.metricsInterval <= 0 {
+		cfg.metricsInterval = DefaultMetricsExportInterval
+	}
+	cfg.metrics = newMetricsRegistry(cfg.metricsTenantLabel)
+	return cfg, nil
+}
+
+// grpcServerOptions returns the options for the plugin's gRPC server.
+func (c *serveConfig) grpcServerOptions() []grpc.ServerOption {
+	opts := append([]grpc.ServerOption(nil), c.serverOptions...)
+	if len(c.unaryInterceptors) > 0 {
+		opts = append(opts, grpc.ChainUnaryInterceptor(c.unaryInterceptors...))
+	}
+	if len(c.streamInterceptors) > 0 {
+		opts = append(opts, grpc.ChainStreamInterceptor(c.streamInterceptors...))
+	}
+	if c.maxRecvMsgSize > 0 {
+		opts = append(opts, grpc.MaxRecvMsgSize(c.maxRecvMsgSize))
+	}
+	if c.maxSendMsgSize > 0 {
+		opts = append(opts, grpc.MaxSendMsgSize(c.maxSendMsgSize))
+	}
+	return opts
+}
+
+// grpcDialOptions returns the options for the capabilities connection.
+func (c *serveConfig) grpcDialOptions() ([]grpc.DialOption, error) {
+	opts, err := c.security.dialOptions(c.capabilitiesAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	var callOpts []grpc.CallOption
+	if c.maxRecvMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallRecvMsgSize(c.maxRecvMsgSize))
+	}
+	if c.maxSendMsgSize > 0 {
+		callOpts = append(callOpts, grpc.MaxCallSendMsgSize(c.maxSendMsgSize))
+	}
+
+	if len(callOpts) > 0 {
+		opts = append(opts, grpc.WithDefaultCallOptions(callOpts...))
+	}
+
+	// Trace and measure each capability call once, around its retries
+	var interceptors []grpc.UnaryClientInterceptor
+	if c.tracerProvider != nil {
+		interceptors = append(interceptors, c.tracing().unaryClientInterceptor())
+	}
+	interceptors = append(interceptors,
+		c.metrics.unaryClientInterceptor(),
+		retryInterceptor(c.retryPolicies, c.logger),
+	)
+
+	opts = append(opts,
+		grpc.WithKeepaliveParams(c.keepalive),
+		grpc.WithConnectParams(c.connectParams),
+		grpc.WithChainUnaryInterceptor(interceptors...),
+	)
+	return append(opts, c.dialOptions...), nil
+}
+
+// tracing returns the tracing configured by the options.
+func (c *serveConfig) tracing() *tracing {
+	return newTracing(c.tracerProvider, c.propagator)
+}
+
+// WithCapabilitiesAddr sets the address of the capabilities service.
+// Defaults to the WABISABY_CAPABILITIES_ADDR environment variable.
+func WithCapabilitiesAddr(addr string) ServeOption {
+	return func(c *serveConfig) {
+		c.capabilitiesAddr = addr
+	}
+}
+
+// WithDialOptions adds gRPC dial options for the capabilities connection.
+func WithDialOptions(opts ...grpc.DialOption) ServeOption {
+	return func(c *serveConfig) {
+		c.dialOptions = append(c.dialOptions, opts...)
+	}
+}
+
+// WithGRPCServerOptions adds gRPC server options for the plugin's gRPC server.
+func WithGRPCServerOptions(opts ...grpc.ServerOption) ServeOption {
+	return func(c *serveConfig) {
+		c.serverOptions = append(c.serverOptions, opts...)
+	}
+}
+
+// WithUnaryInterceptors adds unary interceptors to the plugin's gRPC server.
+// Interceptors run in the order given.
+func WithUnaryInterceptors(interceptors ...grpc.UnaryServerInterceptor) ServeOption {
+	return func(c *serveConfig) {
+		c.unaryInterceptors = append(c.unaryInterceptors, interceptors...)
+	}
+}
+
+// WithStreamInterceptors adds stream interceptors to the plugin's gRPC server.
+// Interceptors run in the order given.
+func WithStreamInterceptors(interceptors ...grpc.StreamServerInterceptor) ServeOption {
+	return func(c *serveConfig) {
+		c.streamInterceptors = append(c.streamInterceptors, interceptors...)
+	}
+}
+
+// WithMaxRecvMsgSize sets the maximum message size in bytes the plugin
+// receives, both from the host and from the capabilities service.
+func WithMaxRecvMsgSize(bytes int) ServeOption {
+	return func(c *serveConfig) {
+		c.maxRecvMsgSize = bytes
+	}
+}
+
+// WithMaxSendMsgSize sets the maximum message size in bytes the plugin
+// sends, both to the host and to the capabilities service.
+func WithMaxSendMsgSize(bytes int) ServeOption {
+	return func(c *serveConfig) {
+		c.maxSendMsgSize = bytes
+	}
+}
+
+// WithLogger sets the logger used by go-plugin and the SDK for the plugin process.
+func WithLogger(logger hclog.Logger) ServeOption {
+	return func(c *serveConfig) {
+		c.logger = logger
+	}
+}
+
+// WithHandshakeConfig replaces the go-plugin handshake configuration.
+// Defaults to HandshakeConfig().
+func WithHandshakeConfig(handshake hashicorp_plugin.HandshakeConfig) ServeOption {
+	return func(c *serveConfig) {
+		c.handshake = handshake
+	}
+}
+
+// WithPanicPolicy sets the policy deciding whether the process exits after
+// recurring panics. Defaults to DefaultPanicPolicy().
+func WithPanicPolicy(policy PanicPolicy) ServeOption {
+	return func(c *serveConfig) {
+		c.panicPolicy = policy
+	}
+}
+
+// WithDrainTimeout sets how long ShutdownPlugin waits for in-flight commands
+// before cancelling them. Defaults to DefaultDrainTimeout.
+func WithDrainTimeout(timeout time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.drainTimeout = timeout
+	}
+}
+
+// WithHealthCacheTTL sets how long a health check result is reused.
+// Defaults to DefaultHealthCacheTTL.
+func WithHealthCacheTTL(ttl time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.healthCacheTTL = ttl
+	}
+}
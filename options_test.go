@@ -0,0 +1,209 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	hashicorp_plugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// serveHealth serves the standard gRPC health service with opts and
+// returns its address.
+func serveHealth(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := grpc.NewServer(opts...)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(lis) }()
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// recorder records the names of the interceptors that ran.
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) record(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, name)
+}
+
+func (r *recorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.calls...)
+}
+
+func (r *recorder) unaryServer(name string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		r.record(name)
+		return handler(ctx, req)
+	}
+}
+
+func (r *recorder) unaryClient(name string) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		r.record(name)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// checkHealthService calls the standard health service at addr.
+func checkHealthService(t *testing.T, conn *grpc.ClientConn) error {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+	defer cancel()
+	_, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	return err
+}
+
+func TestServeConfigDefaults(t *testing.T) {
+	t.Setenv("WABISABY_CAPABILITIES_ADDR", "capabilities:50051")
+
+	config, err := newServeConfig()
+	must(t, err)
+	if config.capabilitiesAddr != "capabilities:50051" {
+		t.Errorf("capabilitiesAddr = %q, want the environment variable", config.capabilitiesAddr)
+	}
+	if config.handshake != HandshakeConfig() {
+		t.Errorf("handshake = %+v, want HandshakeConfig()", config.handshake)
+	}
+	if config.logger == nil {
+		t.Error("logger is nil, want a default logger")
+	}
+	if config.drainTimeout != DefaultDrainTimeout {
+		t.Errorf("drainTimeout = %v, want %v", config.drainTimeout, DefaultDrainTimeout)
+	}
+	if len(config.grpcServerOptions()) != 0 {
+		t.Errorf("grpcServerOptions() = %d options, want none", len(config.grpcServerOptions()))
+	}
+}
+
+func TestServeOptionsOverrideDefaults(t *testing.T) {
+	t.Setenv("WABISABY_CAPABILITIES_ADDR", "capabilities:50051")
+	handshake := hashicorp_plugin.HandshakeConfig{
+		ProtocolVersion:  7,
+		MagicCookieKey:   "CUSTOM_COOKIE",
+		MagicCookieValue: "custom",
+	}
+
+	config, err := newServeConfig(
+		WithCapabilitiesAddr("127.0.0.1:9000"),
+		WithHandshakeConfig(handshake),
+	)
+	must(t, err)
+	if config.capabilitiesAddr != "127.0.0.1:9000" {
+		t.Errorf("capabilitiesAddr = %q, want the option", config.capabilitiesAddr)
+	}
+	if config.handshake != handshake {
+		t.Errorf("handshake = %+v, want %+v", config.handshake, handshake)
+	}
+}
+
+func TestNewServerUsesOptions(t *testing.T) {
+	policy := PanicPolicy{MaxPanics: 2, Window: time.Minute}
+	server := newTestServer(t, &BasePlugin{},
+		WithDrainTimeout(time.Second),
+		WithPanicPolicy(policy),
+	)
+
+	if server.config.drainTimeout != time.Second {
+		t.Errorf("drainTimeout = %v, want 1s", server.config.drainTimeout)
+	}
+	if got := server.panics.policy; got.MaxPanics != policy.MaxPanics || got.Window != policy.Window {
+		t.Errorf("panic policy = %+v, want %+v", got, policy)
+	}
+}
+
+func TestNewServerRequiresCapabilitiesAddr(t *testing.T) {
+	t.Setenv("WABISABY_CAPABILITIES_ADDR", "")
+
+	_, err := NewServer(&BasePlugin{})
+	if err == nil || !strings.Contains(err.Error(), "WABISABY_CAPABILITIES_ADDR") {
+		t.Fatalf("NewServer() error = %v, want a missing address error", err)
+	}
+}
+
+func TestUnaryInterceptorsRunInOrder(t *testing.T) {
+	var rec recorder
+	config, err := newServeConfig(
+		WithUnaryInterceptors(rec.unaryServer("first"), rec.unaryServer("second")),
+		WithUnaryInterceptors(rec.unaryServer("third")),
+	)
+	must(t, err)
+	addr := serveHealth(t, config.grpcServerOptions()...)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	must(t, err)
+	defer conn.Close()
+	must(t, checkHealthService(t, conn))
+
+	if got := strings.Join(rec.get(), ","); got != "first,second,third" {
+		t.Fatalf("interceptors ran as %s, want first,second,third", got)
+	}
+}
+
+func TestMaxRecvMsgSizeLimitsServer(t *testing.T) {
+	config, err := newServeConfig(WithMaxRecvMsgSize(8))
+	must(t, err)
+	addr := serveHealth(t, config.grpcServerOptions()...)
+
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	must(t, err)
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(testContext(t), 5*time.Second)
+	defer cancel()
+	_, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{
+		Service: strings.Repeat("x", 64),
+	})
+	if status.Code(err) != codes.ResourceExhausted {
+		t.Fatalf("oversized request error = %v, want RESOURCE_EXHAUSTED", err)
+	}
+}
+
+func TestDialOptionsApplyToCapabilitiesConnection(t *testing.T) {
+	var rec recorder
+	addr := serveHealth(t)
+
+	server, err := NewServer(&BasePlugin{},
+		WithCapabilitiesAddr(addr),
+		WithDialOptions(grpc.WithChainUnaryInterceptor(rec.unaryClient("dial"))),
+	)
+	must(t, err)
+	defer server.Close()
+
+	must(t, checkHealthService(t, server.capabilitiesConn))
+	if got := rec.get(); len(got) != 1 || got[0] != "dial" {
+		t.Fatalf("dial interceptor calls = %v, want one", got)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"errors"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// PanicPolicy decides whether the plugin process stays alive after panics.
+// Panics are always recovered and reported to the host as PANIC errors;
+// the policy only decides when recurring panics should stop the process.
+type PanicPolicy struct {
+	// MaxPanics is the number of panics within Window after which the
+	// process exits. Zero keeps the process alive regardless of panics.
+	MaxPanics int
+
+	// Window is the time window panics are counted in. Zero counts all
+	// panics since the process started.
+	Window time.Duration
+
+	// ExitCode is the exit code used when the process exits.
+	ExitCode int
+}
+
+// DefaultPanicPolicy returns the default panic policy, which keeps the
+// process alive regardless of panics.
+func DefaultPanicPolicy() PanicPolicy {
+	return PanicPolicy{
+		Window:   time.Minute,
+		ExitCode: 2,
+	}
+}
+
+// recoverPanic calls fn, converting a panic into a PANIC error.
+// The panic value and stack trace are logged to the local logger of the
+// plugin process, since the capabilities service may be unreachable or the
+// call's context cancelled.
+func recoverPanic(logger hclog.Logger, operation string, fn func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			stack := debug.Stack()
+			err = NewError(CodePanic, "panic in %s: %v", operation, r)
+			logger.Error("recovered from panic",
+				"operation", operation,
+				"panic", r,
+				"stack", string(stack),
+			)
+		}
+	}()
+	return fn()
+}
+
+// isPanic checks if err reports a recovered panic.
+func isPanic(err error) bool {
+	var sdkErr *Error
+	return errors.As(err, &sdkErr) && sdkErr.Code == CodePanic
+}
+
+// panicGuard counts recovered panics and applies the panic policy.
+type panicGuard struct {
+	mu     sync.Mutex
+	policy PanicPolicy
+	times  []time.Time
+	logger hclog.Logger
+	exit   func(code int)
+
+	// onPanic is called for every recovered panic, if set
+	onPanic func()
+}
+
+// newPanicGuard creates a panic guard with the default policy, logging
+// to logger before it exits the process.
+func newPanicGuard(logger hclog.Logger) *panicGuard {
+	return &panicGuard{
+		policy: DefaultPanicPolicy(),
+		logger: logger,
+		exit:   os.Exit,
+	}
+}
+
+// setPolicy replaces the panic policy.
+func (g *panicGuard) setPolicy(policy PanicPolicy) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.policy = policy
+	g.times = nil
+}
+
+// observe records err if it reports a recovered panic, and exits the
+// process once the policy's limit is reached.
+func (g *panicGuard) observe(err error) {
+	if !isPanic(err) {
+		return
+	}
+	if g.onPanic != nil {
+		g.onPanic()
+	}
+
+	g.mu.Lock()
+	policy := g.policy
+	if policy.MaxPanics <= 0 {
+		g.mu.Unlock()
+		return
+	}
+	now := time.Now()
+
+	// Drop panics that fell out of the window; without a window, only the
+	// latest panics matter for the limit
+	kept := g.times[:0]
+	for _, t := range g.times {
+		if policy.Window <= 0 || now.Sub(t) < policy.Window {
+			kept = append(kept, t)
+		}
+	}
+	if len(kept) >= policy.MaxPanics {
+		kept = kept[len(kept)-policy.MaxPanics+1:]
+	}
+	g.times = append(kept, now)
+	count := len(g.times)
+	g.mu.Unlock()
+
+	if count >= policy.MaxPanics {
+		within := "since start"
+		if policy.Window > 0 {
+			within = "within " + policy.Window.String()
+		}
+		g.logger.Error("plugin exiting after repeated panics",
+			"panics", count,
+			"within", within,
+			"error", err,
+		)
+		g.exit(policy.ExitCode)
+	}
+}
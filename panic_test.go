@@ -0,0 +1,112 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent writes.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestCommandPanicIsRecoveredAndLoggedLocally(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("boom", func(ctx *Context) (interface{}, error) {
+		panic("kaboom")
+	}))
+	var logs syncBuffer
+	server := newTestServer(t, plugin, WithLogger(hclog.New(&hclog.LoggerOptions{Output: &logs})))
+
+	err := newTestTarget().execute(t, server, testContext(t), nil, "boom")
+	if err.GetCode() != string(CodePanic) || !strings.Contains(err.Message, "kaboom") {
+		t.Fatalf("panicking command failed with %+v, want PANIC", err)
+	}
+	if out := logs.String(); !strings.Contains(out, "recovered from panic") || !strings.Contains(out, "panic_test.go") {
+		t.Fatalf("panic not logged with its stack trace to the process logger:\n%s", out)
+	}
+	if got := counterValue(server.Metrics(), MetricPanicsTotal, nil); got != 1 {
+		t.Fatalf("panics metric = %d, want 1", got)
+	}
+}
+
+func TestRecoverPanicPassesErrorsThrough(t *testing.T) {
+	want := errors.New("failed")
+	if err := recoverPanic(hclog.NewNullLogger(), "op", func() error { return want }); err != want {
+		t.Fatalf("recoverPanic = %v, want %v", err, want)
+	}
+	if isPanic(want) {
+		t.Fatal("plain error reported as panic")
+	}
+}
+
+func TestPanicGuardPolicy(t *testing.T) {
+	panicErr := NewError(CodePanic, "panic in test")
+	tests := []struct {
+		name     string
+		policy   PanicPolicy
+		panics   int
+		wantExit bool
+	}{
+		{"default keeps the process alive", DefaultPanicPolicy(), 100, false},
+		{"limit within window", PanicPolicy{MaxPanics: 3, Window: time.Minute, ExitCode: 7}, 3, true},
+		{"below limit", PanicPolicy{MaxPanics: 3, Window: time.Minute, ExitCode: 7}, 2, false},
+		{"zero window counts since start", PanicPolicy{MaxPanics: 2, ExitCode: 7}, 2, true},
+		{"panics outside the window", PanicPolicy{MaxPanics: 2, Window: time.Nanosecond, ExitCode: 7}, 5, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs syncBuffer
+			guard := newPanicGuard(hclog.New(&hclog.LoggerOptions{Output: &logs}))
+			exitCode := -1
+			guard.exit = func(code int) { exitCode = code }
+			guard.setPolicy(tt.policy)
+
+			for i := 0; i < tt.panics; i++ {
+				guard.observe(panicErr)
+				time.Sleep(time.Microsecond)
+			}
+			guard.observe(errors.New("not a panic"))
+
+			if exited := exitCode != -1; exited != tt.wantExit {
+				t.Fatalf("exited = %v (code %d), want %v", exited, exitCode, tt.wantExit)
+			}
+			if tt.wantExit && exitCode != tt.policy.ExitCode {
+				t.Fatalf("exit code = %d, want %d", exitCode, tt.policy.ExitCode)
+			}
+			if logged := strings.Contains(logs.String(), "[ERROR] plugin exiting after repeated panics"); logged != tt.wantExit {
+				t.Fatalf("exit logged = %v, want %v:\n%s", logged, tt.wantExit, logs.String())
+			}
+		})
+	}
+}
@@ -11,6 +11,10 @@
 
 package sdk
 
+import (
+	"context"
+)
+
 // Plugin is the base interface that all plugins must implement.
 type Plugin interface {
 	// Initialize is called when the plugin is first loaded.
@@ -36,12 +40,16 @@ type CommandPlugin interface {
 // and only override the methods they need.
 type BasePlugin struct {
 	router *CommandRouter
+	events *EventRouter
+	health *HealthChecks
 }
 
 // NewBasePlugin creates a new BasePlugin instance.
 func NewBasePlugin() *BasePlugin {
 	return &BasePlugin{
 		router: NewCommandRouter(),
+		events: NewEventRouter(),
+		health: NewHealthChecks(),
 	}
 }
 
@@ -76,6 +84,39 @@ func (p *BasePlugin) RegisterCommand(name string, handler CommandHandler, opts .
 	return p.router.Register(name, handler, opts...)
 }
 
+// Use adds middleware wrapping every registered command.
+// This is a convenience method for plugins embedding BasePlugin.
+func (p *BasePlugin) Use(mw ...Middleware) {
+	if p.router == nil {
+		p.router = NewCommandRouter()
+	}
+	p.router.Use(mw...)
+}
+
+// DisableIntrospection stops the router from answering the reserved
+// introspection commands, and the server from answering __manifest.
+// Plugins overriding ExecuteCommand can use it to handle those names
+// themselves. The metrics, job and cancel commands are still answered.
+func (p *BasePlugin) DisableIntrospection() {
+	if p.router == nil {
+		p.router = NewCommandRouter()
+	}
+	p.router.DisableIntrospection()
+}
+
+// IntrospectionDisabled checks if DisableIntrospection was called.
+func (p *BasePlugin) IntrospectionDisabled() bool {
+	return p.router != nil && p.router.IntrospectionDisabled()
+}
+
+// HasCommand checks if a command is registered.
+func (p *BasePlugin) HasCommand(name string) bool {
+	if p.router == nil {
+		return false
+	}
+	return p.router.HasCommand(name)
+}
+
 // GetCommands returns metadata for all registered commands.
 func (p *BasePlugin) GetCommands() []CommandMetadata {
 	if p.router == nil {
@@ -83,3 +124,49 @@ func (p *BasePlugin) GetCommands() []CommandMetadata {
 	}
 	return p.router.GetCommands()
 }
+
+// GetCommand returns the metadata of a registered command.
+func (p *BasePlugin) GetCommand(name string) (CommandMetadata, bool) {
+	if p.router == nil {
+		return CommandMetadata{}, false
+	}
+	return p.router.GetCommand(name)
+}
+
+// HandleEvent handles an event delivered over the events stream.
+// Routes to registered event handlers if available, otherwise acknowledges
+// the event as NOT_SUPPORTED.
+// Override this method to provide custom event handling logic.
+func (p *BasePlugin) HandleEvent(ctx *Context, event *Event) error {
+	if p.events == nil {
+		return NewError(CodeNotSupported, "no handler registered for event %q", event.Type)
+	}
+	return p.events.Dispatch(ctx, event)
+}
+
+// OnEvent registers an event handler with the event router.
+// This is a convenience method for plugins embedding BasePlugin.
+func (p *BasePlugin) OnEvent(eventType string, handler EventHandler) error {
+	if p.events == nil {
+		p.events = NewEventRouter()
+	}
+	return p.events.On(eventType, handler)
+}
+
+// CheckHealth runs the registered health checks.
+// Override this method to provide custom health checking logic.
+func (p *BasePlugin) CheckHealth(ctx context.Context) error {
+	if p.health == nil {
+		return nil
+	}
+	return p.health.CheckHealth(ctx)
+}
+
+// RegisterHealthCheck registers a named health check reported to the host.
+// This is a convenience method for plugins embedding BasePlugin.
+func (p *BasePlugin) RegisterHealthCheck(name string, check HealthCheckFunc) error {
+	if p.health == nil {
+		p.health = NewHealthChecks()
+	}
+	return p.health.Register(name, check)
+}
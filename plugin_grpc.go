@@ -24,12 +24,30 @@ type PluginGRPC struct {
 	plugin.Plugin
 	// Impl will be set by the plugin binary
 	Impl pluginpb.PluginExecutionServiceServer
+
+	// Version is the protocol version served, zero for ProtocolVersion
+	Version int
+
+	// handler registers the services of Version, nil for ProtocolVersion
+	handler ProtocolHandler
 }
 
 // GRPCServer registers the gRPC server
+// go-plugin only calls it for the negotiated protocol version, which is
+// recorded on the Server.
 func (p *PluginGRPC) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
-	pluginpb.RegisterPluginExecutionServiceServer(s, p.Impl)
-	return nil
+	server, ok := p.Impl.(*Server)
+	if !ok || p.handler == nil {
+		pluginpb.RegisterPluginExecutionServiceServer(s, p.Impl)
+		return nil
+	}
+
+	version := p.Version
+	if version == 0 {
+		version = ProtocolVersion
+	}
+	server.protocolVersion.Store(int32(version))
+	return p.handler(server, s)
 }
 
 // GRPCClient creates a gRPC client
@@ -40,7 +58,7 @@ func (p *PluginGRPC) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker,
 // HandshakeConfig returns the handshake configuration
 func HandshakeConfig() plugin.HandshakeConfig {
 	return plugin.HandshakeConfig{
-		ProtocolVersion:  1,
+		ProtocolVersion:  ProtocolVersion,
 		MagicCookieKey:   "WABISABY_PLUGIN",
 		MagicCookieValue: "wabisaby-plugin-runtime",
 	}
@@ -0,0 +1,185 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultProgressInterval is the minimum time between two progress
+	// updates of a command sent to the host.
+	DefaultProgressInterval = 500 * time.Millisecond
+
+	// progressBufferSize is the number of updates buffered per host stream.
+	// Updates are dropped for a stream whose buffer is full.
+	progressBufferSize = 64
+)
+
+// ProgressReporter reports the progress of a long-running command to the host.
+type ProgressReporter interface {
+	// Report reports that the command is percent complete, from 0 to 100,
+	// with a message describing the current step.
+	Report(percent float64, message string)
+}
+
+// WithProgressInterval sets the minimum time between two progress updates
+// of a command. Defaults to DefaultProgressInterval.
+func WithProgressInterval(interval time.Duration) ServeOption {
+	return func(c *serveConfig) {
+		c.progressInterval = interval
+	}
+}
+
+// Progress reports the progress of the command to the host, as percent
+// complete from 0 to 100 with a message. Updates are throttled, and only
+// reach the host while it streams progress.
+//
+// The StreamProgress RPC needs the wabisaby_protos_next build tag. Without
+// it the host never receives progress, and updates only reach dev mode and
+// the records of async jobs.
+func (c *Context) Progress(percent float64, message string) {
+	c.ProgressReporter().Report(percent, message)
+}
+
+// ProgressReporter returns the reporter of the command's progress.
+// It is never nil.
+func (c *Context) ProgressReporter() ProgressReporter {
+	if c.progress == nil {
+		return noopProgress{}
+	}
+	return c.progress
+}
+
+// noopProgress discards progress updates.
+type noopProgress struct{}
+
+// Report implements ProgressReporter.
+func (noopProgress) Report(percent float64, message string) {}
+
+// progressUpdate is a progress update of a command.
+type progressUpdate struct {
+	requestID string
+	tenantID  string
+	pluginID  string
+	command   string
+	percent   float64
+	message   string
+	timestamp time.Time
+}
+
+// progressHub fans progress updates out to the host streams.
+type progressHub struct {
+	interval time.Duration
+
+	mu          sync.RWMutex
+	subscribers map[chan progressUpdate]string // tenant ID filter
+}
+
+// newProgressHub creates a hub throttling each command to one update per interval.
+func newProgressHub(interval time.Duration) *progressHub {
+	return &progressHub{
+		interval:    interval,
+		subscribers: make(map[chan progressUpdate]string),
+	}
+}
+
+// subscribe registers a host stream for the updates of tenantID, or of all
+// tenants if empty. The returned function unregisters it.
+func (h *progressHub) subscribe(tenantID string) (<-chan progressUpdate, func()) {
+	updates := make(chan progressUpdate, progressBufferSize)
+	h.mu.Lock()
+	h.subscribers[updates] = tenantID
+	h.mu.Unlock()
+
+	return updates, func() {
+		h.mu.Lock()
+		delete(h.subscribers, updates)
+		h.mu.Unlock()
+	}
+}
+
+// active checks if any host stream is subscribed.
+func (h *progressHub) active() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.subscribers) > 0
+}
+
+// publish sends update to the subscribed streams without blocking.
+func (h *progressHub) publish(update progressUpdate) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for updates, tenantID := range h.subscribers {
+		if tenantID != "" && tenantID != update.tenantID {
+			continue
+		}
+		select {
+		case updates <- update:
+		default:
+		}
+	}
+}
+
+// reporter returns the progress reporter of a command.
+func (h *progressHub) reporter(ctx *Context, command string) *commandProgress {
+	return &commandProgress{
+		hub:       h,
+		requestID: ctx.Request.RequestID,
+		tenantID:  ctx.TenantID.String(),
+		pluginID:  ctx.PluginID.String(),
+		command:   command,
+	}
+}
+
+// commandProgress reports the progress of one command, tagged with its
+// request ID.
+type commandProgress struct {
+	hub       *progressHub
+	requestID string
+	tenantID  string
+	pluginID  string
+	command   string
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+// Report implements ProgressReporter.
+// Updates arriving within the interval of the previous one are dropped,
+// except completion at 100 percent.
+func (p *commandProgress) Report(percent float64, message string) {
+	if !p.hub.active() {
+		return
+	}
+	percent = min(max(percent, 0), 100)
+
+	now := time.Now()
+	p.mu.Lock()
+	if percent < 100 && !p.last.IsZero() && now.Sub(p.last) < p.hub.interval {
+		p.mu.Unlock()
+		return
+	}
+	p.last = now
+	p.mu.Unlock()
+
+	p.hub.publish(progressUpdate{
+		requestID: p.requestID,
+		tenantID:  p.tenantID,
+		pluginID:  p.pluginID,
+		command:   p.command,
+		percent:   percent,
+		message:   message,
+		timestamp: now,
+	})
+}
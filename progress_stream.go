@@ -0,0 +1,54 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+)
+
+// The StreamProgress RPC is not in the released protos. Without the
+// wabisaby_protos_next tag, no host stream subscribes and progress
+// reports do nothing.
+
+// StreamProgress implements PluginExecutionServiceServer.StreamProgress.
+// The host receives the progress updates of running commands, of all tenants
+// unless the request names one, until it closes the stream.
+func (s *Server) StreamProgress(req *pluginpb.StreamProgressRequest, stream pluginpb.PluginExecutionService_StreamProgressServer) error {
+	updates, unsubscribe := s.progress.subscribe(req.TenantId)
+	defer unsubscribe()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case update := <-updates:
+			if err := stream.Send(update.proto()); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// proto converts the update for the host.
+func (u progressUpdate) proto() *pluginpb.ProgressUpdate {
+	return &pluginpb.ProgressUpdate{
+		RequestId:   u.requestID,
+		TenantId:    u.tenantID,
+		PluginId:    u.pluginID,
+		Command:     u.command,
+		Percent:     u.percent,
+		Message:     u.message,
+		TimestampMs: u.timestamp.UnixMilli(),
+	}
+}
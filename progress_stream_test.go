@@ -0,0 +1,67 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+//go:build wabisaby_protos_next
+
+package sdk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+)
+
+// fakeProgressStream records the updates sent to the host.
+type fakeProgressStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent chan *pluginpb.ProgressUpdate
+}
+
+func (f *fakeProgressStream) Context() context.Context {
+	return f.ctx
+}
+
+func (f *fakeProgressStream) Send(update *pluginpb.ProgressUpdate) error {
+	f.sent <- update
+	return nil
+}
+
+func TestStreamProgressSendsUpdates(t *testing.T) {
+	server := newTestServer(t, progressPlugin(t), WithProgressInterval(time.Hour))
+	target := newTestTarget()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &fakeProgressStream{ctx: ctx, sent: make(chan *pluginpb.ProgressUpdate, progressBufferSize)}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.StreamProgress(&pluginpb.StreamProgressRequest{TenantId: target.tenantID.String()}, stream)
+	}()
+	waitFor(t, server.progress.active)
+
+	target.run(t, server, nil, "long")
+	first, last := <-stream.sent, <-stream.sent
+	if first.Percent != 0 || last.Percent != 100 || last.Command != "long" || last.TenantId != target.tenantID.String() {
+		t.Fatalf("updates %+v then %+v, want the first report and completion", first, last)
+	}
+	if last.RequestId == "" || last.TimestampMs == 0 {
+		t.Fatalf("update %+v, want its request ID and timestamp", last)
+	}
+
+	cancel()
+	must(t, <-done)
+	if server.progress.active() {
+		t.Fatal("stream still subscribed after the host closed it")
+	}
+}
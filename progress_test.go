@@ -0,0 +1,116 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// progressPlugin has a "long" command reporting its progress in steps of
+// 10 percent, every 5ms.
+func progressPlugin(t *testing.T) *BasePlugin {
+	t.Helper()
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("long", func(ctx *Context) (interface{}, error) {
+		for percent := 0; percent <= 100; percent += 10 {
+			ctx.Progress(float64(percent), "step")
+			time.Sleep(5 * time.Millisecond)
+		}
+		return nil, nil
+	}))
+	return plugin
+}
+
+// drain returns the updates buffered in updates.
+func drain(updates <-chan progressUpdate) []progressUpdate {
+	var received []progressUpdate
+	for len(updates) > 0 {
+		received = append(received, <-updates)
+	}
+	return received
+}
+
+func TestProgressThrottledAndTagged(t *testing.T) {
+	server := newTestServer(t, progressPlugin(t), WithProgressInterval(time.Hour))
+	target := newTestTarget()
+	updates, unsubscribe := server.progress.subscribe("")
+	defer unsubscribe()
+
+	ctx := metadata.NewIncomingContext(testContext(t), metadata.Pairs(MetadataRequestID, "req-1"))
+	if perr := target.execute(t, server, ctx, nil, "long"); perr != nil {
+		t.Fatalf("long failed: %v", perr)
+	}
+
+	received := drain(updates)
+	if len(received) != 2 || received[0].percent != 0 || received[1].percent != 100 {
+		t.Fatalf("received %+v, want the first report and completion only", received)
+	}
+	for _, update := range received {
+		if update.requestID != "req-1" || update.command != "long" || update.tenantID != target.tenantID.String() {
+			t.Fatalf("update %+v, want it tagged with the call", update)
+		}
+	}
+}
+
+func TestProgressFilteredByTenant(t *testing.T) {
+	server := newTestServer(t, progressPlugin(t))
+	target := newTestTarget()
+	mine, unsubscribeMine := server.progress.subscribe(target.tenantID.String())
+	defer unsubscribeMine()
+	other, unsubscribeOther := server.progress.subscribe(uuid.NewString())
+	defer unsubscribeOther()
+
+	target.run(t, server, nil, "long")
+	if len(mine) == 0 {
+		t.Error("no updates for the stream of the tenant")
+	}
+	if len(other) != 0 {
+		t.Errorf("%d updates for the stream of another tenant, want none", len(other))
+	}
+}
+
+func TestProgressWithoutStreamDoesNothing(t *testing.T) {
+	server := newTestServer(t, progressPlugin(t))
+	target := newTestTarget()
+	target.run(t, server, nil, "long")
+
+	// A stream subscribing later receives none of the earlier updates
+	updates, unsubscribe := server.progress.subscribe("")
+	defer unsubscribe()
+	if len(updates) != 0 {
+		t.Fatalf("%d updates buffered without a stream, want none", len(updates))
+	}
+
+	// A context of no command discards progress
+	var ctx Context
+	ctx.Progress(50, "nowhere")
+}
+
+func TestDownloadReceivesProgressReporter(t *testing.T) {
+	server := newTestServer(t, &testProgressDownloader{NewContentDownloaderPlugin()})
+	updates, unsubscribe := server.progress.subscribe("")
+	defer unsubscribe()
+
+	var result DownloadResult
+	newTestTarget().run(t, server, &result, CommandDownload, "https://example.com/a")
+	if result.FilePath != "/tmp/https://example.com/a" {
+		t.Fatalf("download returned %+v, want the DownloadWithProgress result", result)
+	}
+	received := drain(updates)
+	if len(received) != 1 || received[0].command != CommandDownload || received[0].percent != 50 {
+		t.Fatalf("updates %+v, want the download's report", received)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	hashicorp_plugin "github.com/hashicorp/go-plugin"
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"google.golang.org/grpc"
+)
+
+// ProtocolVersion is the wire protocol version implemented by Server.
+const ProtocolVersion = 1
+
+// ProtocolHandler registers the gRPC services of one protocol version on s.
+// Handlers for other versions than ProtocolVersion adapt server to the
+// services of their version.
+type ProtocolHandler func(server *Server, s *grpc.Server) error
+
+// registerCurrentProtocol registers server as the current protocol version.
+func registerCurrentProtocol(server *Server, s *grpc.Server) error {
+	pluginpb.RegisterPluginExecutionServiceServer(s, server)
+	return nil
+}
+
+// WithProtocolVersion advertises an additional protocol version to the host,
+// served by handler. The host and plugin negotiate the highest version both
+// support, so one binary can serve hosts on several versions.
+func WithProtocolVersion(version int, handler ProtocolHandler) ServeOption {
+	return func(c *serveConfig) {
+		c.protocols[version] = handler
+	}
+}
+
+// ProtocolVersion returns the protocol version negotiated with the host,
+// or zero before the host connected.
+func (s *Server) ProtocolVersion() int {
+	return int(s.protocolVersion.Load())
+}
+
+// versionedPlugins returns the plugin set of every advertised protocol version.
+func (s *Server) versionedPlugins() map[int]hashicorp_plugin.PluginSet {
+	plugins := make(map[int]hashicorp_plugin.PluginSet, len(s.config.protocols))
+	for version, handler := range s.config.protocols {
+		plugins[version] = hashicorp_plugin.PluginSet{
+			"plugin": &PluginGRPC{
+				Impl:    s,
+				Version: version,
+				handler: handler,
+			},
+		}
+	}
+	return plugins
+}
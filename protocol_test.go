@@ -0,0 +1,95 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"sort"
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+// advertisedVersions returns the protocol versions server advertises, sorted.
+func advertisedVersions(server *Server) []int {
+	var versions []int
+	for version := range server.versionedPlugins() {
+		versions = append(versions, version)
+	}
+	sort.Ints(versions)
+	return versions
+}
+
+// negotiate registers the plugin set of version on a gRPC server, as
+// go-plugin does for the version negotiated with the host.
+func negotiate(t *testing.T, server *Server, version int) {
+	t.Helper()
+
+	set, ok := server.versionedPlugins()[version]
+	if !ok {
+		t.Fatalf("protocol version %d is not advertised", version)
+	}
+	grpcServer := grpc.NewServer()
+	defer grpcServer.Stop()
+	must(t, set["plugin"].(*PluginGRPC).GRPCServer(nil, grpcServer))
+}
+
+func TestProtocolVersionsAdvertised(t *testing.T) {
+	server := newTestServer(t, NewBasePlugin())
+	if got := advertisedVersions(server); len(got) != 1 || got[0] != ProtocolVersion {
+		t.Fatalf("advertised versions = %v, want [%d]", got, ProtocolVersion)
+	}
+
+	adapter := func(server *Server, s *grpc.Server) error { return nil }
+	server = newTestServer(t, NewBasePlugin(), WithProtocolVersion(ProtocolVersion+1, adapter))
+	if got := advertisedVersions(server); len(got) != 2 || got[0] != ProtocolVersion || got[1] != ProtocolVersion+1 {
+		t.Fatalf("advertised versions = %v, want [%d %d]", got, ProtocolVersion, ProtocolVersion+1)
+	}
+}
+
+func TestProtocolVersionNegotiated(t *testing.T) {
+	var adapted *Server
+	adapter := func(server *Server, s *grpc.Server) error {
+		adapted = server
+		return nil
+	}
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("version", func(ctx *Context) (interface{}, error) {
+		return ctx.ProtocolVersion, nil
+	}))
+	server := newTestServer(t, plugin, WithProtocolVersion(ProtocolVersion+1, adapter))
+	if got := server.ProtocolVersion(); got != 0 {
+		t.Fatalf("ProtocolVersion() before negotiation = %d, want 0", got)
+	}
+
+	negotiate(t, server, ProtocolVersion+1)
+	if adapted != server {
+		t.Fatal("the adapter of the negotiated version was not called with the server")
+	}
+	if got := server.ProtocolVersion(); got != ProtocolVersion+1 {
+		t.Fatalf("ProtocolVersion() = %d, want %d", got, ProtocolVersion+1)
+	}
+
+	var version int
+	newTestTarget().run(t, server, &version, "version")
+	if version != ProtocolVersion+1 {
+		t.Fatalf("ctx.ProtocolVersion = %d, want %d", version, ProtocolVersion+1)
+	}
+}
+
+func TestCurrentProtocolVersionNegotiated(t *testing.T) {
+	server := newTestServer(t, NewBasePlugin())
+
+	negotiate(t, server, ProtocolVersion)
+	if got := server.ProtocolVersion(); got != ProtocolVersion {
+		t.Fatalf("ProtocolVersion() = %d, want %d", got, ProtocolVersion)
+	}
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+// gRPC metadata keys describing the host request behind a call.
+const (
+	MetadataRequestID   = "x-request-id"
+	MetadataUserID      = "x-wabisaby-user-id"
+	MetadataUserRoles   = "x-wabisaby-user-roles"
+	MetadataLocale      = "x-wabisaby-locale"
+	MetadataTraceParent = "traceparent"
+	MetadataTraceState  = "tracestate"
+)
+
+// RequestInfo describes the host request that triggered a call.
+type RequestInfo struct {
+	// RequestID identifies the request in host and plugin logs.
+	// Generated by the SDK if the host did not send one.
+	RequestID string
+
+	// UserID is the ID of the user who triggered the call, empty if the
+	// call was not triggered by a user.
+	UserID string
+
+	// Roles are the roles of the user who triggered the call.
+	Roles []string
+
+	// Locale is the preferred locale of the user, such as "en-US".
+	Locale string
+
+	// TraceParent and TraceState are the W3C trace context of the request.
+	TraceParent string
+	TraceState  string
+}
+
+// HasRole checks if the user who triggered the call has role.
+func (r *RequestInfo) HasRole(role string) bool {
+	if r == nil {
+		return false
+	}
+	for _, have := range r.Roles {
+		if have == role {
+			return true
+		}
+	}
+	return false
+}
+
+// TraceID returns the trace ID of the request's trace context, or an empty
+// string if the request has no valid trace context.
+func (r *RequestInfo) TraceID() string {
+	if r == nil {
+		return ""
+	}
+	// version-traceid-parentid-flags
+	parts := strings.Split(r.TraceParent, "-")
+	if len(parts) < 4 || len(parts[1]) != 32 {
+		return ""
+	}
+	return parts[1]
+}
+
+// requestInfoKey is the context key of the request info of an RPC.
+type requestInfoKey struct{}
+
+// withRequestInfo returns the request info of the RPC behind ctx and a
+// context carrying it. The first call for an RPC reads the info from the
+// incoming metadata, generating a request ID if the host sent none, and
+// passes the request ID on to capability calls; later calls reuse it.
+func withRequestInfo(ctx context.Context) (context.Context, *RequestInfo) {
+	if info, ok := ctx.Value(requestInfoKey{}).(*RequestInfo); ok {
+		return ctx, info
+	}
+
+	info := requestInfoFromMetadata(ctx)
+	ctx = context.WithValue(ctx, requestInfoKey{}, info)
+	ctx = metadata.AppendToOutgoingContext(ctx, MetadataRequestID, info.RequestID)
+	return ctx, info
+}
+
+// requestInfoFromMetadata reads the request info from the incoming gRPC
+// metadata of ctx, generating a request ID if none was sent.
+func requestInfoFromMetadata(ctx context.Context) *RequestInfo {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	info := &RequestInfo{
+		RequestID:   firstMetadata(md, MetadataRequestID),
+		UserID:      firstMetadata(md, MetadataUserID),
+		Locale:      firstMetadata(md, MetadataLocale),
+		TraceParent: firstMetadata(md, MetadataTraceParent),
+		TraceState:  firstMetadata(md, MetadataTraceState),
+	}
+	if info.RequestID == "" {
+		info.RequestID = uuid.NewString()
+	}
+
+	// Roles may be sent as repeated values, comma-separated, or both
+	for _, value := range md.Get(MetadataUserRoles) {
+		for _, role := range strings.Split(value, ",") {
+			if role = strings.TrimSpace(role); role != "" {
+				info.Roles = append(info.Roles, role)
+			}
+		}
+	}
+	return info
+}
+
+// firstMetadata returns the first value of key in md, or an empty string.
+func firstMetadata(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
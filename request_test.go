@@ -0,0 +1,108 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestInfoFromMetadata(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		MetadataRequestID, "req-1",
+		MetadataUserID, "user-1",
+		MetadataUserRoles, "admin, dj",
+		MetadataUserRoles, "listener",
+		MetadataLocale, "en-US",
+		MetadataTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+
+	pluginCtx := NewContext(ctx, uuid.New(), uuid.New(), nil, nil)
+	request := pluginCtx.Request
+	if request.RequestID != "req-1" || request.UserID != "user-1" || request.Locale != "en-US" {
+		t.Fatalf("request = %+v, want the metadata values", request)
+	}
+	if len(request.Roles) != 3 || !request.HasRole("dj") || !request.HasRole("listener") {
+		t.Fatalf("roles = %v, want admin, dj and listener", request.Roles)
+	}
+	if got := request.TraceID(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("TraceID() = %q, want the trace ID of the traceparent", got)
+	}
+	if fields := pluginCtx.Logger.fields(); fields["request_id"] != "req-1" {
+		t.Fatalf("logger fields = %v, want the request ID", fields)
+	}
+}
+
+func TestRequestIDSharedAcrossContextsOfACall(t *testing.T) {
+	ctx, request := withRequestInfo(context.Background())
+	if request.RequestID == "" {
+		t.Fatal("no request ID generated")
+	}
+
+	first := NewContext(ctx, uuid.New(), uuid.New(), nil, nil)
+	second := NewContext(ctx, uuid.New(), uuid.New(), nil, nil)
+	nested := NewContext(first, uuid.New(), uuid.New(), nil, nil)
+	for _, pluginCtx := range []*Context{first, second, nested} {
+		if pluginCtx.Request.RequestID != request.RequestID {
+			t.Fatalf("request ID = %q, want %q", pluginCtx.Request.RequestID, request.RequestID)
+		}
+	}
+
+	// The request ID is sent on once, however many contexts are created
+	out, _ := metadata.FromOutgoingContext(nested)
+	if got := out.Get(MetadataRequestID); len(got) != 1 || got[0] != request.RequestID {
+		t.Fatalf("outgoing request IDs = %v, want [%s]", got, request.RequestID)
+	}
+}
+
+func TestExecuteCommandRequestID(t *testing.T) {
+	type observed struct {
+		RequestID   string   `json:"request_id"`
+		NestedID    string   `json:"nested_id"`
+		OutgoingIDs []string `json:"outgoing_ids"`
+	}
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("observe", func(ctx *Context) (interface{}, error) {
+		nested := NewContext(ctx, ctx.TenantID, ctx.PluginID, nil, nil)
+		out, _ := metadata.FromOutgoingContext(nested)
+		return &observed{
+			RequestID:   ctx.Request.RequestID,
+			NestedID:    nested.Request.RequestID,
+			OutgoingIDs: out.Get(MetadataRequestID),
+		}, nil
+	}))
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	// Generated when the host sends none
+	var generated observed
+	target.run(t, server, &generated, "observe")
+	if generated.RequestID == "" || generated.NestedID != generated.RequestID {
+		t.Fatalf("observed %+v, want one generated request ID", generated)
+	}
+	if len(generated.OutgoingIDs) != 1 || generated.OutgoingIDs[0] != generated.RequestID {
+		t.Fatalf("outgoing request IDs = %v, want [%s]", generated.OutgoingIDs, generated.RequestID)
+	}
+
+	// Taken from the host otherwise
+	ctx := metadata.NewIncomingContext(testContext(t), metadata.Pairs(MetadataRequestID, "host-req"))
+	var sent observed
+	if perr := target.execute(t, server, ctx, &sent, "observe"); perr != nil {
+		t.Fatalf("observe failed: %v", perr)
+	}
+	if sent.RequestID != "host-req" || sent.NestedID != "host-req" || len(sent.OutgoingIDs) != 1 {
+		t.Fatalf("observed %+v, want the host request ID once", sent)
+	}
+}
@@ -0,0 +1,201 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/backoff"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/status"
+)
+
+// RetryPolicy configures retries of a capability call.
+// Only idempotent calls should be retried.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	MaxAttempts int
+
+	// InitialBackoff is the delay before the first retry.
+	InitialBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries.
+	MaxBackoff time.Duration
+
+	// Multiplier grows the delay after each retry.
+	Multiplier float64
+
+	// Jitter randomizes each delay by up to this fraction in either direction.
+	Jitter float64
+
+	// RetryableCodes are the gRPC status codes that trigger a retry.
+	RetryableCodes []codes.Code
+}
+
+// DefaultRetryPolicy returns the retry policy used for idempotent capability calls.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     2 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableCodes: []codes.Code{codes.Unavailable},
+	}
+}
+
+// idempotentCapabilityMethods are the capability methods that are safe to
+// retry by default. Calls that change state, such as QueueAdd, are never
+// retried unless a policy is configured for them explicitly.
+var idempotentCapabilityMethods = []string{
+	"StorageGet",
+	"StorageKeys",
+	"SecretGet",
+	"QueueGet",
+	"SongSearch",
+	"SongGet",
+	"UserGet",
+}
+
+// defaultRetryPolicies returns the default retry policy of each idempotent method.
+func defaultRetryPolicies() map[string]RetryPolicy {
+	policies := make(map[string]RetryPolicy, len(idempotentCapabilityMethods))
+	for _, method := range idempotentCapabilityMethods {
+		policies[method] = DefaultRetryPolicy()
+	}
+	return policies
+}
+
+// DefaultKeepaliveParams returns the keepalive parameters of the capabilities
+// connection. The ping interval stays within gRPC's default server
+// enforcement policy so the host does not reject the pings.
+func DefaultKeepaliveParams() keepalive.ClientParameters {
+	return keepalive.ClientParameters{
+		Time:    5 * time.Minute,
+		Timeout: 20 * time.Second,
+	}
+}
+
+// DefaultConnectParams returns the connection backoff parameters of the
+// capabilities connection.
+func DefaultConnectParams() grpc.ConnectParams {
+	return grpc.ConnectParams{
+		Backoff: backoff.Config{
+			BaseDelay:  500 * time.Millisecond,
+			Multiplier: 1.6,
+			Jitter:     0.2,
+			MaxDelay:   10 * time.Second,
+		},
+		MinConnectTimeout: 5 * time.Second,
+	}
+}
+
+// retryInterceptor returns a client interceptor retrying calls according to
+// the policy of their method. Methods without a policy are called once.
+func retryInterceptor(policies map[string]RetryPolicy, logger hclog.Logger) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		name := method[strings.LastIndex(method, "/")+1:]
+		policy, ok := policies[name]
+		if !ok || policy.MaxAttempts <= 1 {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		delay := policy.InitialBackoff
+		for attempt := 1; ; attempt++ {
+			err := invoker(ctx, method, req, reply, cc, opts...)
+			if err == nil {
+				if attempt > 1 {
+					logger.Debug("capability call succeeded after retries", "method", name, "retries", attempt-1)
+				}
+				return nil
+			}
+
+			if attempt >= policy.MaxAttempts || !policy.retryable(err) {
+				if attempt > 1 {
+					logger.Warn("capability call failed after retries", "method", name, "retries", attempt-1, "error", err)
+				}
+				return err
+			}
+
+			// Never wait past the caller's deadline
+			wait := policy.jittered(delay)
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < wait {
+				return err
+			}
+
+			logger.Debug("retrying capability call", "method", name, "attempt", attempt+1, "backoff", wait, "error", err)
+
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return err
+			case <-timer.C:
+			}
+
+			delay = time.Duration(float64(delay) * policy.Multiplier)
+			if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+				delay = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// retryable checks if err has one of the policy's retryable codes.
+func (p RetryPolicy) retryable(err error) bool {
+	code := status.Code(err)
+	for _, retryable := range p.RetryableCodes {
+		if code == retryable {
+			return true
+		}
+	}
+	return false
+}
+
+// jittered randomizes delay by up to the policy's jitter fraction.
+func (p RetryPolicy) jittered(delay time.Duration) time.Duration {
+	if p.Jitter <= 0 {
+		return delay
+	}
+	factor := 1 + p.Jitter*(2*rand.Float64()-1)
+	return time.Duration(float64(delay) * factor)
+}
+
+// WithRetryPolicy sets the retry policy of a capability method, such as
+// "StorageGet". A policy with MaxAttempts of 1 disables retries for the method.
+func WithRetryPolicy(method string, policy RetryPolicy) ServeOption {
+	return func(c *serveConfig) {
+		c.retryPolicies[method] = policy
+	}
+}
+
+// WithKeepaliveParams sets the keepalive parameters of the capabilities connection.
+// Defaults to DefaultKeepaliveParams().
+func WithKeepaliveParams(params keepalive.ClientParameters) ServeOption {
+	return func(c *serveConfig) {
+		c.keepalive = params
+	}
+}
+
+// WithConnectParams sets the connection backoff parameters of the capabilities
+// connection. Defaults to DefaultConnectParams().
+func WithConnectParams(params grpc.ConnectParams) ServeOption {
+	return func(c *serveConfig) {
+		c.connectParams = params
+	}
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fastRetryPolicy retries quickly so that tests stay fast.
+func fastRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+// failingInvoker fails with the given codes in turn, then succeeds.
+// It counts the attempts in *attempts.
+func failingInvoker(attempts *int, failures ...codes.Code) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*attempts++
+		if *attempts <= len(failures) {
+			return status.Error(failures[*attempts-1], "failed")
+		}
+		return nil
+	}
+}
+
+// invokeWithRetries calls method through a retry interceptor with policies.
+func invokeWithRetries(ctx context.Context, policies map[string]RetryPolicy, logger hclog.Logger, method string, invoker grpc.UnaryInvoker) error {
+	interceptor := retryInterceptor(policies, logger)
+	return interceptor(ctx, "/wabisaby.plugin.PluginCapabilitiesService/"+method, nil, nil, nil, invoker)
+}
+
+func TestRetryIdempotentCallUntilSuccess(t *testing.T) {
+	var attempts int
+	var logs syncBuffer
+	logger := hclog.New(&hclog.LoggerOptions{Output: &logs, Level: hclog.Debug})
+	policies := map[string]RetryPolicy{"StorageGet": fastRetryPolicy()}
+
+	err := invokeWithRetries(testContext(t), policies, logger, "StorageGet",
+		failingInvoker(&attempts, codes.Unavailable, codes.Unavailable))
+	if err != nil {
+		t.Fatalf("call failed: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("attempts = %d, want 3", attempts)
+	}
+	if !strings.Contains(logs.String(), "retries=2") {
+		t.Fatalf("log does not report the retry count:\n%s", logs.String())
+	}
+}
+
+func TestRetryStopsAtMaxAttempts(t *testing.T) {
+	var attempts int
+	policies := map[string]RetryPolicy{"SongSearch": fastRetryPolicy()}
+
+	err := invokeWithRetries(testContext(t), policies, hclog.NewNullLogger(), "SongSearch",
+		failingInvoker(&attempts, codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable, codes.Unavailable))
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("error = %v, want UNAVAILABLE", err)
+	}
+	if attempts != fastRetryPolicy().MaxAttempts {
+		t.Fatalf("attempts = %d, want %d", attempts, fastRetryPolicy().MaxAttempts)
+	}
+}
+
+func TestRetrySkipsNonRetryableCodes(t *testing.T) {
+	var attempts int
+	policies := map[string]RetryPolicy{"QueueGet": fastRetryPolicy()}
+
+	err := invokeWithRetries(testContext(t), policies, hclog.NewNullLogger(), "QueueGet",
+		failingInvoker(&attempts, codes.InvalidArgument))
+	if status.Code(err) != codes.InvalidArgument || attempts != 1 {
+		t.Fatalf("error = %v after %d attempts, want INVALID_ARGUMENT after 1", err, attempts)
+	}
+}
+
+func TestRetryNeverRetriesNonIdempotentCalls(t *testing.T) {
+	var attempts int
+
+	err := invokeWithRetries(testContext(t), defaultRetryPolicies(), hclog.NewNullLogger(), "QueueAdd",
+		failingInvoker(&attempts, codes.Unavailable))
+	if status.Code(err) != codes.Unavailable || attempts != 1 {
+		t.Fatalf("error = %v after %d attempts, want UNAVAILABLE after 1", err, attempts)
+	}
+}
+
+func TestRetryRespectsDeadline(t *testing.T) {
+	var attempts int
+	policy := fastRetryPolicy()
+	policy.InitialBackoff = time.Second
+	policies := map[string]RetryPolicy{"StorageGet": policy}
+
+	ctx, cancel := context.WithTimeout(testContext(t), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := invokeWithRetries(ctx, policies, hclog.NewNullLogger(), "StorageGet",
+		failingInvoker(&attempts, codes.Unavailable, codes.Unavailable))
+	if status.Code(err) != codes.Unavailable || attempts != 1 {
+		t.Fatalf("error = %v after %d attempts, want UNAVAILABLE after 1", err, attempts)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("call took %v, want it to give up before the deadline", elapsed)
+	}
+}
+
+func TestRetryPolicyOptionDisablesRetries(t *testing.T) {
+	var attempts int
+	config, err := newServeConfig(WithRetryPolicy("StorageGet", RetryPolicy{MaxAttempts: 1}))
+	must(t, err)
+
+	err = invokeWithRetries(testContext(t), config.retryPolicies, hclog.NewNullLogger(), "StorageGet",
+		failingInvoker(&attempts, codes.Unavailable))
+	if status.Code(err) != codes.Unavailable || attempts != 1 {
+		t.Fatalf("error = %v after %d attempts, want UNAVAILABLE after 1", err, attempts)
+	}
+}
+
+func TestRetryPolicyJitterStaysInBounds(t *testing.T) {
+	policy := RetryPolicy{Jitter: 0.2}
+	for i := 0; i < 100; i++ {
+		wait := policy.jittered(100 * time.Millisecond)
+		if wait < 80*time.Millisecond || wait > 120*time.Millisecond {
+			t.Fatalf("jittered delay = %v, want within 20%% of 100ms", wait)
+		}
+	}
+}
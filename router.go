@@ -34,8 +34,12 @@ type registeredCommand struct {
 
 // CommandRouter manages command registration and routing.
 type CommandRouter struct {
-	mu       sync.RWMutex
-	commands map[string]*registeredCommand
+	mu         sync.RWMutex
+	commands   map[string]*registeredCommand
+	middleware []Middleware
+
+	// introspectionDisabled stops the router from answering reserved commands
+	introspectionDisabled bool
 }
 
 // NewCommandRouter creates a new command router.
@@ -46,11 +50,15 @@ func NewCommandRouter() *CommandRouter {
 }
 
 // Register registers a command with its handler and options.
-// Returns an error if the command is already registered or the handler signature is invalid.
+// Returns an error if the command is already registered, its name is
+// reserved, or the handler signature is invalid.
 func (r *CommandRouter) Register(name string, handler CommandHandler, opts ...CommandOption) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if isReservedCommand(name) {
+		return fmt.Errorf("command name %q is reserved: names starting with %q belong to the SDK", name, ReservedCommandPrefix)
+	}
 	if _, exists := r.commands[name]; exists {
 		return fmt.Errorf("command %q already registered", name)
 	}
@@ -108,65 +116,118 @@ func (r *CommandRouter) Register(name string, handler CommandHandler, opts ...Co
 	return nil
 }
 
+// Use adds middleware wrapping every command routed by the router.
+// Middleware runs in the order added, before any command middleware.
+func (r *CommandRouter) Use(mw ...Middleware) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.middleware = append(r.middleware, mw...)
+}
+
 // Route routes a command to its handler and returns the result.
 func (r *CommandRouter) Route(ctx *Context, command string, args []interface{}) (interface{}, error) {
+	// Answer reserved introspection commands
+	if isReservedCommand(command) {
+		if result, ok, err := r.introspect(command, args); ok {
+			return result, err
+		}
+	}
+
 	r.mu.RLock()
 	cmd, exists := r.commands[command]
+	middleware := r.middleware
 	r.mu.RUnlock()
 
 	if !exists {
-		return nil, fmt.Errorf("unknown command: %s", command)
+		return nil, NotFound("unknown command: %s", command)
+	}
+
+	decoded, err := r.decodeArgs(cmd, args)
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Invocation{
+		Command:  cmd.metadata.Name,
+		Metadata: cmd.metadata,
+		RawArgs:  args,
+		Args:     decoded,
 	}
 
-	return r.invokeHandler(ctx, cmd, args)
+	invoke := func(ctx *Context, inv *Invocation) (interface{}, error) {
+		return r.invokeHandler(ctx, cmd, inv.Args)
+	}
+	invoke = chainMiddleware(invoke, cmd.metadata.middleware...)
+	invoke = chainMiddleware(invoke, middleware...)
+
+	return invoke(ctx, inv)
 }
 
-// invokeHandler calls the handler with proper argument marshaling.
-func (r *CommandRouter) invokeHandler(ctx *Context, cmd *registeredCommand, args []interface{}) (interface{}, error) {
-	handlerVal := reflect.ValueOf(cmd.handler)
-	handlerType := handlerVal.Type()
+// decodeArgs decodes args into the handler's argument type.
+// Returns nil if the handler takes no arguments beyond Context.
+func (r *CommandRouter) decodeArgs(cmd *registeredCommand, args []interface{}) (interface{}, error) {
+	if cmd.argType == nil {
+		return nil, nil
+	}
 
-	var callArgs []reflect.Value
-	callArgs = append(callArgs, reflect.ValueOf(ctx))
+	argPtr := reflect.New(cmd.argType)
+
+	if len(args) > 0 {
+		var argsMap map[string]interface{}
 
-	// If handler expects typed arguments, unmarshal them
-	if cmd.argType != nil && handlerType.NumIn() == 2 {
-		argPtr := reflect.New(cmd.argType)
-
-		if len(args) > 0 {
-			var argsMap map[string]interface{}
-
-			// Check if first arg is already a map
-			if m, ok := args[0].(map[string]interface{}); ok {
-				argsMap = m
-			} else {
-				// Construct map from positional args using parameter metadata
-				argsMap = make(map[string]interface{})
-				for i, param := range cmd.metadata.Parameters {
-					if i < len(args) {
-						argsMap[param.Name] = args[i]
-					} else if param.Default != nil {
-						argsMap[param.Name] = param.Default
-					}
+		// Check if first arg is already a map
+		if m, ok := args[0].(map[string]interface{}); ok {
+			argsMap = m
+		} else {
+			// Construct map from positional args using parameter metadata
+			argsMap = make(map[string]interface{})
+			for i, param := range cmd.metadata.Parameters {
+				if i < len(args) {
+					argsMap[param.Name] = args[i]
+				} else if param.Default != nil {
+					argsMap[param.Name] = param.Default
 				}
 			}
+		}
 
-			// Marshal to JSON then unmarshal to typed struct
-			jsonBytes, err := json.Marshal(argsMap)
-			if err != nil {
-				return nil, fmt.Errorf("failed to marshal arguments: %w", err)
-			}
-			if err := json.Unmarshal(jsonBytes, argPtr.Interface()); err != nil {
-				return nil, fmt.Errorf("failed to unmarshal arguments to %s: %w", cmd.argType.Name(), err)
-			}
+		// Marshal to JSON then unmarshal to typed struct
+		jsonBytes, err := json.Marshal(argsMap)
+		if err != nil {
+			return nil, InvalidArgument("failed to marshal arguments").WithCause(err)
 		}
+		if err := json.Unmarshal(jsonBytes, argPtr.Interface()); err != nil {
+			return nil, InvalidArgument("failed to unmarshal arguments to %s", cmd.argType.Name()).WithCause(err)
+		}
+	}
+
+	return argPtr.Interface(), nil
+}
+
+// invokeHandler calls the handler with the decoded arguments.
+func (r *CommandRouter) invokeHandler(ctx *Context, cmd *registeredCommand, args interface{}) (interface{}, error) {
+	handlerVal := reflect.ValueOf(cmd.handler)
+	handlerType := handlerVal.Type()
 
-		// Pass pointer to the handler
-		callArgs = append(callArgs, argPtr)
+	var callArgs []reflect.Value
+	callArgs = append(callArgs, reflect.ValueOf(ctx))
+
+	// Pass the decoded arguments to the handler
+	if handlerType.NumIn() == 2 {
+		if args == nil {
+			callArgs = append(callArgs, reflect.Zero(handlerType.In(1)))
+		} else {
+			callArgs = append(callArgs, reflect.ValueOf(args))
+		}
 	}
 
-	// Call the handler
-	results := handlerVal.Call(callArgs)
+	// Call the handler, recovering from panics
+	var results []reflect.Value
+	if err := recoverPanic(ctx.localLogger(), "command "+cmd.metadata.Name, func() error {
+		results = handlerVal.Call(callArgs)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
 
 	// Extract return values
 	var result interface{}
@@ -184,18 +245,44 @@ func (r *CommandRouter) invokeHandler(ctx *Context, cmd *registeredCommand, args
 	return result, err
 }
 
-// GetCommands returns metadata for all registered commands.
+// GetCommands returns metadata for all registered commands, sorted by name.
 func (r *CommandRouter) GetCommands() []CommandMetadata {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
 	commands := make([]CommandMetadata, 0, len(r.commands))
-	for _, cmd := range r.commands {
-		commands = append(commands, cmd.metadata)
+	for _, name := range sortedKeys(r.commands) {
+		commands = append(commands, r.commands[name].metadata)
 	}
 	return commands
 }
 
+// GetCommand returns the metadata of a registered command.
+func (r *CommandRouter) GetCommand(name string) (CommandMetadata, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, exists := r.commands[name]
+	if !exists {
+		return CommandMetadata{}, false
+	}
+	return cmd.metadata, true
+}
+
+// commandDescriber is implemented by command executors exposing the
+// metadata of their commands, such as CommandRouter and BasePlugin.
+type commandDescriber interface {
+	GetCommand(name string) (CommandMetadata, bool)
+}
+
+// commandMetadata returns the metadata of a command if executor exposes it.
+func commandMetadata(executor interface{}, name string) (CommandMetadata, bool) {
+	describer, ok := executor.(commandDescriber)
+	if !ok {
+		return CommandMetadata{}, false
+	}
+	return describer.GetCommand(name)
+}
+
 // HasCommand checks if a command is registered.
 func (r *CommandRouter) HasCommand(name string) bool {
 	r.mu.RLock()
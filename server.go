@@ -17,13 +17,13 @@ import (
 	"fmt"
 	"os"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	hashicorp_plugin "github.com/hashicorp/go-plugin"
 	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
 	"google.golang.org/grpc"
-	"google.golang.org/grpc/credentials/insecure"
 )
 
 // Server wraps a plugin implementation and provides the gRPC server.
@@ -31,43 +31,138 @@ type Server struct {
 	pluginpb.UnimplementedPluginExecutionServiceServer
 
 	plugin             Plugin
+	config             *serveConfig
 	capabilitiesClient pluginpb.PluginCapabilitiesServiceClient
 	capabilitiesConn   *grpc.ClientConn
+	events             *eventDispatcher
+
+	// Live instances of stateful plugins, keyed by tenant and plugin ID
+	instances *instanceRegistry
+	enableMu  sync.Mutex
+
+	// Latest config of each tenant/plugin pair, attached to every Context
+	configs *configCache
+
+	// Recovered panics, counted against the panic policy
+	panics *panicGuard
+
+	// Running commands, drained before the plugin shuts down
+	inflight *inflightTracker
+
+	// Cached result of the last health check
+	health *healthCache
+
+	// Per-tenant and per-command concurrency limits
+	limits *concurrencyLimits
+
+	// Spans of command executions
+	tracing *tracing
+
+	// Command and capability call metrics, and their periodic export
+	metrics       *metricsRegistry
+	stopExport    chan struct{}
+	exportDone    chan struct{}
+	closeExporter sync.Once
+
+	// Progress updates of running commands, streamed to the host
+	progress *progressHub
+
+	// Cancel functions of running commands, by request ID
+	cancels *cancelRegistry
+
+	// Jobs of asynchronous commands running in the process
+	jobs *jobRegistry
+
+	// Protocol version negotiated with the host
+	protocolVersion atomic.Int32
 
 	// Initialization state tracking
 	initOnce     sync.Once
+	initMu       sync.RWMutex
+	initialized  bool
 	initErr      error
 	shutdownOnce sync.Once
 }
 
 // NewServer creates a new plugin server.
-// It connects to the capabilities service using WABISABY_CAPABILITIES_ADDR environment variable.
-func NewServer(plugin Plugin) (*Server, error) {
+// It connects to the capabilities service using WABISABY_CAPABILITIES_ADDR environment variable,
+// unless another address is given with WithCapabilitiesAddr.
+func NewServer(plugin Plugin, opts ...ServeOption) (*Server, error) {
+	config, err := newServeConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return newServer(plugin, config)
+}
+
+// newServer creates a server for plugin from a built configuration.
+func newServer(plugin Plugin, config *serveConfig) (*Server, error) {
+	// Expose specialized interfaces through their standard commands
+	if err := registerSpecializedCommands(plugin); err != nil {
+		return nil, fmt.Errorf("failed to register specialized commands: %w", err)
+	}
+
 	// Connect to capabilities service
-	capabilitiesAddr := os.Getenv("WABISABY_CAPABILITIES_ADDR")
-	if capabilitiesAddr == "" {
+	if config.capabilitiesAddr == "" {
 		return nil, fmt.Errorf("WABISABY_CAPABILITIES_ADDR environment variable not set")
 	}
 
-	conn, err := grpc.NewClient(
-		capabilitiesAddr,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
+	dialOpts, err := config.grpcDialOptions()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure capabilities connection: %w", err)
+	}
+
+	conn, err := grpc.NewClient(config.capabilitiesAddr, dialOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to capabilities service: %w", err)
 	}
 
 	capabilitiesClient := pluginpb.NewPluginCapabilitiesServiceClient(conn)
 
-	return &Server{
+	server := &Server{
 		plugin:             plugin,
+		config:             config,
 		capabilitiesClient: capabilitiesClient,
 		capabilitiesConn:   conn,
-	}, nil
+		instances:          newInstanceRegistry(),
+		configs:            newConfigCache(),
+		panics:             newPanicGuard(config.logger),
+		inflight:           newInflightTracker(),
+		health:             &healthCache{ttl: config.healthCacheTTL},
+		limits:             newConcurrencyLimits(config.tenantConcurrency, config.tenantQueue),
+		tracing:            config.tracing(),
+		metrics:            config.metrics,
+		progress:           newProgressHub(config.progressInterval),
+		jobs:               newJobRegistry(config.jobRetention),
+		cancels:            newCancelRegistry(),
+	}
+	server.events = newEventDispatcher(server)
+	server.panics.setPolicy(config.panicPolicy)
+	server.panics.onPanic = server.metrics.panicked
+
+	if config.metricsExporter != nil {
+		server.stopExport = make(chan struct{})
+		server.exportDone = make(chan struct{})
+		go server.metrics.export(config.metricsExporter, config.metricsInterval, server.stopExport, server.exportDone)
+	}
+
+	return server, nil
+}
+
+// Metrics returns a snapshot of the command and capability call metrics.
+func (s *Server) Metrics() MetricsSnapshot {
+	return s.metrics.snapshot()
 }
 
-// Close closes the capabilities connection.
+// Close exports the metrics a last time if an exporter is configured, and
+// closes the capabilities connection.
 func (s *Server) Close() error {
+	if s.stopExport != nil {
+		s.closeExporter.Do(func() {
+			close(s.stopExport)
+			<-s.exportDone
+		})
+	}
 	if s.capabilitiesConn != nil {
 		return s.capabilitiesConn.Close()
 	}
@@ -76,14 +171,95 @@ func (s *Server) Close() error {
 
 // ExecuteCommand implements PluginExecutionServiceServer.ExecuteCommand.
 func (s *Server) ExecuteCommand(ctx context.Context, req *pluginpb.ExecuteCommandRequest) (*pluginpb.ExecuteCommandResponse, error) {
+	// Every context created for the call shares its request ID
+	ctx, _ = withRequestInfo(ctx)
+
+	ctx, span := s.tracing.startCommand(ctx, req)
+	scope := newCommandScope(req.TenantId)
+	ctx = context.WithValue(ctx, commandScopeKey{}, scope)
+	commandEnded := s.metrics.commandStarted()
+	start := time.Now()
+
+	resp, err := s.executeCommand(ctx, req)
+
+	code := outcomeOK
+	if pluginErr := resp.GetError(); pluginErr != nil {
+		code = pluginErr.Code
+	}
+	commandEnded(scope, code, time.Since(start))
+	s.tracing.endCommand(span, resp)
+	return resp, err
+}
+
+// reservedCommand answers a reserved command of the server.
+// Returns false if command is not one. The manifest is introspection, left
+// to plugins that disabled it; the metrics, job and cancel commands are
+// always answered.
+func (s *Server) reservedCommand(ctx context.Context, key instanceKey, req *pluginpb.ExecuteCommandRequest) (*pluginpb.ExecuteCommandResponse, bool) {
+	if !isReservedCommand(req.Command) {
+		return nil, false
+	}
+
+	switch req.Command {
+	case MetricsCommand:
+		return reservedResponse(s.metrics.snapshot(), nil), true
+	case ManifestCommand:
+		if introspectionDisabled(s.plugin) {
+			return nil, false
+		}
+		return reservedResponse(BuildManifest(s.plugin)), true
+	case JobStatusCommand, JobCancelCommand, JobListCommand:
+		return s.jobCommand(ctx, key, req), true
+	case CancelCommand:
+		return s.cancelCommand(key, req), true
+	}
+	return nil, false
+}
+
+// reservedResponse returns the response of a reserved command.
+func reservedResponse(result interface{}, err error) *pluginpb.ExecuteCommandResponse {
+	if err != nil {
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: toPluginError(err, CodeExecutionError),
+			},
+		}
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: NewError(CodeSerializationError, "failed to marshal result: %v", err).pluginError(),
+			},
+		}
+	}
+	return &pluginpb.ExecuteCommandResponse{
+		Result: &pluginpb.ExecuteCommandResponse_Data{
+			Data: data,
+		},
+	}
+}
+
+// executeCommand executes a command within its span.
+func (s *Server) executeCommand(ctx context.Context, req *pluginpb.ExecuteCommandRequest) (*pluginpb.ExecuteCommandResponse, error) {
+	// Track the call so that shutdown can drain it
+	callCtx, done, ok := s.inflight.begin(ctx)
+	if !ok {
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, Unavailable("plugin is shutting down"), CodeExecutionError),
+			},
+		}, nil
+	}
+	defer done()
+	ctx = callCtx
+
 	tenantID, err := uuid.Parse(req.TenantId)
 	if err != nil {
 		return &pluginpb.ExecuteCommandResponse{
 			Result: &pluginpb.ExecuteCommandResponse_Error{
-				Error: &pluginpb.PluginError{
-					Code:    "INVALID_ARGUMENT",
-					Message: fmt.Sprintf("invalid tenant ID: %v", err),
-				},
+				Error: commandError(ctx, InvalidArgument("invalid tenant ID: %v", err), CodeExecutionError),
 			},
 		}, nil
 	}
@@ -92,23 +268,27 @@ func (s *Server) ExecuteCommand(ctx context.Context, req *pluginpb.ExecuteComman
 	if err != nil {
 		return &pluginpb.ExecuteCommandResponse{
 			Result: &pluginpb.ExecuteCommandResponse_Error{
-				Error: &pluginpb.PluginError{
-					Code:    "INVALID_ARGUMENT",
-					Message: fmt.Sprintf("invalid plugin ID: %v", err),
-				},
+				Error: commandError(ctx, InvalidArgument("invalid plugin ID: %v", err), CodeExecutionError),
 			},
 		}, nil
 	}
 
-	// Check if plugin implements CommandPlugin
-	commandPlugin, ok := s.plugin.(CommandPlugin)
-	if !ok {
+	// Reserved commands are answered by the server itself, whether or
+	// not the tenant's instance is enabled
+	key := instanceKey{tenantID: tenantID, pluginID: pluginID}
+	if resp, ok := s.reservedCommand(ctx, key, req); ok {
+		if scope, ok := ctx.Value(commandScopeKey{}).(*commandScope); ok {
+			scope.command = req.Command
+		}
+		return resp, nil
+	}
+
+	// Resolve the tenant's instance or the plugin itself
+	executor, entry, err := s.commandExecutor(key)
+	if err != nil {
 		return &pluginpb.ExecuteCommandResponse{
 			Result: &pluginpb.ExecuteCommandResponse_Error{
-				Error: &pluginpb.PluginError{
-					Code:    "NOT_SUPPORTED",
-					Message: "plugin does not support command execution",
-				},
+				Error: commandError(ctx, err, CodeNotSupported),
 			},
 		}, nil
 	}
@@ -120,84 +300,117 @@ func (s *Server) ExecuteCommand(ctx context.Context, req *pluginpb.ExecuteComman
 		if err := json.Unmarshal(argBytes, &arg); err != nil {
 			return &pluginpb.ExecuteCommandResponse{
 				Result: &pluginpb.ExecuteCommandResponse_Error{
-					Error: &pluginpb.PluginError{
-						Code:    "INVALID_ARGUMENT",
-						Message: fmt.Sprintf("failed to unmarshal argument: %v", err),
-					},
+					Error: commandError(ctx, InvalidArgument("failed to unmarshal argument: %v", err), CodeExecutionError),
 				},
 			}, nil
 		}
 		args = append(args, arg)
 	}
 
-	// Create execution context with timeout if specified
-	execCtx := ctx
+	// Label metrics with the command once it is known to exist
+	if scope, ok := ctx.Value(commandScopeKey{}).(*commandScope); ok && knownCommand(executor, req.Command) {
+		scope.command = req.Command
+	}
+
+	// Run asynchronous commands as background jobs
+	cmd, _ := commandMetadata(executor, req.Command)
+	if cmd.Async {
+		return s.startJob(ctx, key, entry, executor, cmd, req.Command, args), nil
+	}
+
+	// Keep the tenant's instance from being disabled while the command runs
+	ctx, endCall, err := entry.begin(ctx)
+	if err != nil {
+		return &pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, err, CodeExecutionError),
+			},
+		}, nil
+	}
+	defer endCall()
+
+	// Let the host cancel the command by its request ID
+	execCtx, unregister := s.cancels.register(ctx, key)
+	defer unregister()
+
+	// Bound the execution by the requested timeout and the command's own timeouts
 	var cancel context.CancelFunc
-	if req.TimeoutMs > 0 {
-		execCtx, cancel = context.WithTimeout(ctx, time.Duration(req.TimeoutMs)*time.Millisecond)
+	if timeout := commandTimeout(time.Duration(req.TimeoutMs)*time.Millisecond, cmd); timeout > 0 {
+		execCtx, cancel = context.WithTimeout(execCtx, timeout)
 		defer cancel()
 	}
 
-	// Create plugin context
-	pluginCtx := NewContext(execCtx, tenantID, pluginID, s.capabilitiesClient, nil)
+	// Wait for a concurrency slot of the tenant and the command
+	release, queueWait, err := s.limits.acquire(execCtx, tenantID, cmd)
+	setQueueWaitTrailer(ctx, queueWait)
+	if err != nil {
+		return withQueueWait(&pluginpb.ExecuteCommandResponse{
+			Result: &pluginpb.ExecuteCommandResponse_Error{
+				Error: commandError(ctx, cancelError(execCtx, deadlineError(execCtx, err)), CodeResourceExhausted),
+			},
+		}, queueWait), nil
+	}
+	defer release()
+
+	// Create plugin context with the tenant's config
+	pluginCtx := s.newContext(execCtx, key)
+	pluginCtx.progress = s.progress.reporter(pluginCtx, req.Command)
 
 	startTime := time.Now()
-	result, err := commandPlugin.ExecuteCommand(pluginCtx, req.Command, args)
+	var result interface{}
+	err = recoverPanic(pluginCtx.localLogger(), "command "+req.Command, func() error {
+		var execErr error
+		result, execErr = executor.ExecuteCommand(pluginCtx, req.Command, args)
+		return execErr
+	})
+	s.panics.observe(err)
+	err = cancelError(execCtx, deadlineError(execCtx, err))
 	executionTime := time.Since(startTime)
 
 	if err != nil {
-		return &pluginpb.ExecuteCommandResponse{
+		return withQueueWait(&pluginpb.ExecuteCommandResponse{
 			Result: &pluginpb.ExecuteCommandResponse_Error{
-				Error: &pluginpb.PluginError{
-					Code:    "EXECUTION_ERROR",
-					Message: err.Error(),
-				},
+				Error: commandError(ctx, err, CodeExecutionError),
 			},
 			ExecutionTimeMs: executionTime.Milliseconds(),
-		}, nil
+		}, queueWait), nil
 	}
 
 	// Marshal result
 	resultJSON, err := json.Marshal(result)
 	if err != nil {
-		return &pluginpb.ExecuteCommandResponse{
+		return withQueueWait(&pluginpb.ExecuteCommandResponse{
 			Result: &pluginpb.ExecuteCommandResponse_Error{
-				Error: &pluginpb.PluginError{
-					Code:    "SERIALIZATION_ERROR",
-					Message: fmt.Sprintf("failed to marshal result: %v", err),
-				},
+				Error: commandError(ctx, NewError(CodeSerializationError, "failed to marshal result: %v", err), CodeExecutionError),
 			},
 			ExecutionTimeMs: executionTime.Milliseconds(),
-		}, nil
+		}, queueWait), nil
 	}
 
-	return &pluginpb.ExecuteCommandResponse{
+	return withQueueWait(&pluginpb.ExecuteCommandResponse{
 		Result: &pluginpb.ExecuteCommandResponse_Data{
 			Data: resultJSON,
 		},
 		ExecutionTimeMs: executionTime.Milliseconds(),
-	}, nil
+	}, queueWait), nil
 }
 
 // EnablePlugin implements PluginExecutionServiceServer.EnablePlugin.
 func (s *Server) EnablePlugin(ctx context.Context, req *pluginpb.EnablePluginRequest) (*pluginpb.EnablePluginResponse, error) {
+	// Every context created for the call shares its request ID
+	ctx, _ = withRequestInfo(ctx)
+
 	tenantID, err := uuid.Parse(req.TenantId)
 	if err != nil {
 		return &pluginpb.EnablePluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid tenant ID: %v", err),
-			},
+			Error: InvalidArgument("invalid tenant ID: %v", err).pluginError(),
 		}, nil
 	}
 
 	pluginID, err := uuid.Parse(req.PluginId)
 	if err != nil {
 		return &pluginpb.EnablePluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid plugin ID: %v", err),
-			},
+			Error: InvalidArgument("invalid plugin ID: %v", err).pluginError(),
 		}, nil
 	}
 
@@ -206,62 +419,183 @@ func (s *Server) EnablePlugin(ctx context.Context, req *pluginpb.EnablePluginReq
 	if len(req.Config) > 0 {
 		if err := json.Unmarshal(req.Config, &config); err != nil {
 			return &pluginpb.EnablePluginResponse{
-				Error: &pluginpb.PluginError{
-					Code:    "INVALID_ARGUMENT",
-					Message: fmt.Sprintf("failed to decode config: %v", err),
-				},
+				Error: InvalidArgument("failed to decode config: %v", err).pluginError(),
 			}, nil
 		}
 	}
 
-	// Create context with capabilities and config
-	// Note: For stateless plugins, this is a no-op, but we create the context
-	// to validate config and ensure it's available if needed
-	_ = NewContext(ctx, tenantID, pluginID, s.capabilitiesClient, config)
+	// Keep the latest config for the tenant's command and event contexts,
+	// replacing a stale one when the tenant is enabled without config
+	key := instanceKey{tenantID: tenantID, pluginID: pluginID}
+	if len(req.Config) > 0 {
+		s.configs.put(key, newTenantConfig(config, req.Config))
+	} else {
+		s.configs.remove(key)
+	}
+
+	statefulPlugin, ok := s.plugin.(StatefulPlugin)
+	if !ok {
+		// For stateless plugins, this is a no-op
+		return &pluginpb.EnablePluginResponse{
+			Success:    true,
+			InstanceId: "default",
+		}, nil
+	}
+
+	s.enableMu.Lock()
+	defer s.enableMu.Unlock()
+
+	// Enabling is idempotent: an already enabled tenant keeps its instance
+	if entry, exists := s.instances.get(key); exists {
+		return &pluginpb.EnablePluginResponse{
+			Success:    true,
+			InstanceId: entry.id,
+		}, nil
+	}
+
+	pluginCtx := s.newContext(ctx, key)
+	var instance Instance
+	err = recoverPanic(pluginCtx.localLogger(), "Enable", func() error {
+		var enableErr error
+		instance, enableErr = statefulPlugin.Enable(pluginCtx, pluginCtx.session.Config)
+		return enableErr
+	})
+	s.panics.observe(err)
+	if err != nil {
+		return &pluginpb.EnablePluginResponse{
+			Error: toPluginError(err, CodeEnableError),
+		}, nil
+	}
+
+	entry := newInstanceEntry(key, instance)
+	s.instances.put(key, entry)
 
-	// For stateless plugins, this is a no-op
-	// Stateful plugins would maintain state here
 	return &pluginpb.EnablePluginResponse{
 		Success:    true,
-		InstanceId: "default",
+		InstanceId: entry.id,
 	}, nil
 }
 
 // DisablePlugin implements PluginExecutionServiceServer.DisablePlugin.
 func (s *Server) DisablePlugin(ctx context.Context, req *pluginpb.DisablePluginRequest) (*pluginpb.DisablePluginResponse, error) {
-	// For stateless plugins, this is a no-op
+	// Every context created for the call shares its request ID
+	ctx, _ = withRequestInfo(ctx)
+
+	tenantID, err := uuid.Parse(req.TenantId)
+	if err != nil {
+		return &pluginpb.DisablePluginResponse{
+			Error: InvalidArgument("invalid tenant ID: %v", err).pluginError(),
+		}, nil
+	}
+
+	pluginID, err := uuid.Parse(req.PluginId)
+	if err != nil {
+		return &pluginpb.DisablePluginResponse{
+			Error: InvalidArgument("invalid plugin ID: %v", err).pluginError(),
+		}, nil
+	}
+
+	s.enableMu.Lock()
+	defer s.enableMu.Unlock()
+
+	// For stateless plugins and tenants that are not enabled, this is a no-op
+	key := instanceKey{tenantID: tenantID, pluginID: pluginID}
+	defer s.configs.remove(key)
+
+	if err := s.disableInstance(ctx, key); err != nil {
+		return &pluginpb.DisablePluginResponse{
+			Error: toPluginError(err, CodeDisableError),
+		}, nil
+	}
+
 	return &pluginpb.DisablePluginResponse{
 		Success: true,
 	}, nil
 }
 
-// StreamEvents implements PluginExecutionServiceServer.StreamEvents.
-// This is for stateful plugins that receive event streams.
-func (s *Server) StreamEvents(stream pluginpb.PluginExecutionService_StreamEventsServer) error {
-	// This would be implemented for stateful plugins
-	// For now, return an error indicating it's not supported
-	return fmt.Errorf("streaming events not yet implemented")
+// disableInstance removes the tenant's instance, waits for the commands and
+// events using it, and tears down its state. Must be called with s.enableMu held.
+func (s *Server) disableInstance(ctx context.Context, key instanceKey) error {
+	statefulPlugin, ok := s.plugin.(StatefulPlugin)
+	if !ok {
+		return nil
+	}
+
+	entry, exists := s.instances.remove(key)
+	if !exists {
+		return nil
+	}
+	drainCtx, cancel := context.WithTimeout(ctx, s.config.drainTimeout)
+	defer cancel()
+	entry.calls.drain(drainCtx)
+
+	pluginCtx := s.newContext(ctx, key)
+	err := recoverPanic(pluginCtx.localLogger(), "Disable", func() error {
+		return statefulPlugin.Disable(pluginCtx, entry.instance)
+	})
+	s.panics.observe(err)
+	return err
+}
+
+// newContext creates a plugin context for the tenant with its cached config.
+func (s *Server) newContext(ctx context.Context, key instanceKey) *Context {
+	var config map[string]interface{}
+	cached := s.configs.get(key)
+	if cached != nil {
+		config = cached.copy()
+	}
+
+	pluginCtx := NewContext(ctx, key.tenantID, key.pluginID, s.capabilitiesClient, config)
+	if cached != nil {
+		pluginCtx.Config.version = cached.version
+	}
+	pluginCtx.ProtocolVersion = s.ProtocolVersion()
+	pluginCtx.processLogger = s.config.logger
+	return pluginCtx
+}
+
+// commandExecutor returns what executes commands for the tenant: its live
+// instance if the instance handles commands, otherwise the plugin itself.
+// Stateful plugins only execute commands of enabled tenants; their instance
+// entry is returned for the command to be tracked against.
+func (s *Server) commandExecutor(key instanceKey) (CommandInstance, *instanceEntry, error) {
+	commandPlugin, handlesCommands := s.plugin.(CommandPlugin)
+	if _, stateful := s.plugin.(StatefulPlugin); !stateful {
+		if !handlesCommands {
+			return nil, nil, NewError(CodeNotSupported, "plugin does not support command execution")
+		}
+		return commandPlugin, nil, nil
+	}
+
+	entry, exists := s.instances.get(key)
+	if !exists {
+		return nil, nil, FailedPrecondition("plugin is not enabled for tenant %s", key.tenantID)
+	}
+	if executor, ok := entry.instance.(CommandInstance); ok {
+		return executor, entry, nil
+	}
+	if !handlesCommands {
+		return nil, nil, NewError(CodeNotSupported, "plugin does not support command execution")
+	}
+	return commandPlugin, entry, nil
 }
 
 // InitializePlugin implements PluginExecutionServiceServer.InitializePlugin.
 func (s *Server) InitializePlugin(ctx context.Context, req *pluginpb.InitializePluginRequest) (*pluginpb.InitializePluginResponse, error) {
+	// Every context created for the call shares its request ID
+	ctx, _ = withRequestInfo(ctx)
+
 	tenantID, err := uuid.Parse(req.TenantId)
 	if err != nil {
 		return &pluginpb.InitializePluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid tenant ID: %v", err),
-			},
+			Error: InvalidArgument("invalid tenant ID: %v", err).pluginError(),
 		}, nil
 	}
 
 	pluginID, err := uuid.Parse(req.PluginId)
 	if err != nil {
 		return &pluginpb.InitializePluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid plugin ID: %v", err),
-			},
+			Error: InvalidArgument("invalid plugin ID: %v", err).pluginError(),
 		}, nil
 	}
 
@@ -270,26 +604,37 @@ func (s *Server) InitializePlugin(ctx context.Context, req *pluginpb.InitializeP
 	if len(req.Config) > 0 {
 		if err := json.Unmarshal(req.Config, &config); err != nil {
 			return &pluginpb.InitializePluginResponse{
-				Error: &pluginpb.PluginError{
-					Code:    "INVALID_ARGUMENT",
-					Message: fmt.Sprintf("failed to decode config: %v", err),
-				},
+				Error: InvalidArgument("failed to decode config: %v", err).pluginError(),
 			}, nil
 		}
 	}
 
+	key := instanceKey{tenantID: tenantID, pluginID: pluginID}
+	if len(req.Config) > 0 {
+		s.configs.put(key, newTenantConfig(config, req.Config))
+	} else {
+		s.configs.remove(key)
+	}
+
 	// Use sync.Once to ensure Initialize is called only once per plugin process
 	s.initOnce.Do(func() {
-		pluginCtx := NewContext(ctx, tenantID, pluginID, s.capabilitiesClient, config)
-		s.initErr = s.plugin.Initialize(pluginCtx)
+		pluginCtx := s.newContext(ctx, key)
+		err := recoverPanic(pluginCtx.localLogger(), "Initialize", func() error {
+			return s.plugin.Initialize(pluginCtx)
+		})
+		s.panics.observe(err)
+
+		s.initMu.Lock()
+		s.initialized, s.initErr = true, err
+		s.initMu.Unlock()
+
+		// Probes before initialization cached NOT_SERVING
+		s.health.invalidate()
 	})
 
-	if s.initErr != nil {
+	if _, initErr := s.initState(); initErr != nil {
 		return &pluginpb.InitializePluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INITIALIZATION_ERROR",
-				Message: s.initErr.Error(),
-			},
+			Error: toPluginError(initErr, CodeInitializationError),
 		}, nil
 	}
 
@@ -298,41 +643,63 @@ func (s *Server) InitializePlugin(ctx context.Context, req *pluginpb.InitializeP
 	}, nil
 }
 
+// initState returns whether initialization completed, and its error.
+func (s *Server) initState() (bool, error) {
+	s.initMu.RLock()
+	defer s.initMu.RUnlock()
+	return s.initialized, s.initErr
+}
+
 // ShutdownPlugin implements PluginExecutionServiceServer.ShutdownPlugin.
 func (s *Server) ShutdownPlugin(ctx context.Context, req *pluginpb.ShutdownPluginRequest) (*pluginpb.ShutdownPluginResponse, error) {
+	// Every context created for the call shares its request ID
+	ctx, _ = withRequestInfo(ctx)
+
 	tenantID, err := uuid.Parse(req.TenantId)
 	if err != nil {
 		return &pluginpb.ShutdownPluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid tenant ID: %v", err),
-			},
+			Error: InvalidArgument("invalid tenant ID: %v", err).pluginError(),
 		}, nil
 	}
 
 	pluginID, err := uuid.Parse(req.PluginId)
 	if err != nil {
 		return &pluginpb.ShutdownPluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "INVALID_ARGUMENT",
-				Message: fmt.Sprintf("invalid plugin ID: %v", err),
-			},
+			Error: InvalidArgument("invalid plugin ID: %v", err).pluginError(),
 		}, nil
 	}
 
 	// Use sync.Once to ensure Shutdown is called only once
 	var shutdownErr error
 	s.shutdownOnce.Do(func() {
-		pluginCtx := NewContext(ctx, tenantID, pluginID, s.capabilitiesClient, nil)
-		shutdownErr = s.plugin.Shutdown(pluginCtx)
+		// Let running commands and queued events finish before the plugin
+		// shuts down, each within the drain timeout, cancelling whatever
+		// outlives it
+		drainCtx, cancel := context.WithTimeout(ctx, s.config.drainTimeout)
+		defer cancel()
+		s.inflight.drain(drainCtx)
+
+		eventsCtx, cancelEvents := context.WithTimeout(ctx, s.config.drainTimeout)
+		defer cancelEvents()
+		s.events.close(eventsCtx)
+
+		// Tear down tenants that are still enabled
+		s.enableMu.Lock()
+		for _, key := range s.instances.keys() {
+			_ = s.disableInstance(ctx, key)
+		}
+		s.enableMu.Unlock()
+
+		pluginCtx := s.newContext(ctx, instanceKey{tenantID: tenantID, pluginID: pluginID})
+		shutdownErr = recoverPanic(pluginCtx.localLogger(), "Shutdown", func() error {
+			return s.plugin.Shutdown(pluginCtx)
+		})
+		s.panics.observe(shutdownErr)
 	})
 
 	if shutdownErr != nil {
 		return &pluginpb.ShutdownPluginResponse{
-			Error: &pluginpb.PluginError{
-				Code:    "SHUTDOWN_ERROR",
-				Message: shutdownErr.Error(),
-			},
+			Error: toPluginError(shutdownErr, CodeShutdownError),
 		}, nil
 	}
 
@@ -342,35 +709,55 @@ func (s *Server) ShutdownPlugin(ctx context.Context, req *pluginpb.ShutdownPlugi
 }
 
 // HealthCheck implements PluginExecutionServiceServer.HealthCheck.
+// The plugin is SERVING once initialized, unless initialization failed,
+// shutdown has started, the capabilities connection is down or one of the
+// plugin's checks fails.
 func (s *Server) HealthCheck(ctx context.Context, req *pluginpb.HealthCheckRequest) (*pluginpb.HealthCheckResponse, error) {
 	return &pluginpb.HealthCheckResponse{
-		Status: pluginpb.HealthCheckResponse_SERVING,
+		Status: s.health.get(ctx, s.checkHealth),
 	}, nil
 }
 
 // Serve starts the plugin server using HashiCorp go-plugin.
 // This is the main entry point for plugin binaries.
-func Serve(plugin Plugin) error {
-	server, err := NewServer(plugin)
+func Serve(plugin Plugin, opts ...ServeOption) error {
+	// Write the manifest instead of serving when run with --manifest
+	if path, ok := manifestPath(os.Args[1:]); ok {
+		return WriteManifest(plugin, path)
+	}
+
+	// Run against a fake capabilities backend when dev mode is requested.
+	// Otherwise go-plugin refuses to run without the host's handshake.
+	dev, devRequested, err := parseDevArgs(os.Args[1:])
 	if err != nil {
-		return fmt.Errorf("failed to create server: %w", err)
+		return err
+	}
+	devEnv, err := devFromEnv()
+	if err != nil {
+		return err
+	}
+	config, err := newServeConfig(opts...)
+	if err != nil {
+		return err
+	}
+	if devRequested || devEnv {
+		return serveDev(plugin, dev, config)
 	}
-	defer server.Close()
 
-	// Create plugin implementation
-	pluginImpl := &PluginGRPC{
-		Impl: server,
+	server, err := newServer(plugin, config)
+	if err != nil {
+		return fmt.Errorf("failed to create server: %w", err)
 	}
+	defer server.Close()
 
-	// Serve using go-plugin
+	// Serve using go-plugin, advertising every supported protocol version
 	hashicorp_plugin.Serve(&hashicorp_plugin.ServeConfig{
-		HandshakeConfig: HandshakeConfig(),
-		Plugins: map[string]hashicorp_plugin.Plugin{
-			"plugin": pluginImpl,
-		},
+		HandshakeConfig:  server.config.handshake,
+		VersionedPlugins: server.versionedPlugins(),
 		GRPCServer: func(opts []grpc.ServerOption) *grpc.Server {
-			return grpc.NewServer(opts...)
+			return grpc.NewServer(append(opts, server.config.grpcServerOptions()...)...)
 		},
+		Logger: server.config.logger,
 	})
 
 	return nil
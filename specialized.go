@@ -26,27 +26,27 @@ func NewContentDownloaderPlugin() *ContentDownloaderPlugin {
 
 // DownloadRequest represents a request to download content.
 type DownloadRequest struct {
-	URL         string // URL to download from
-	Format      string // Desired format (e.g., "mp3", "mp4")
-	Quality     string // Desired quality (e.g., "high", "medium", "low")
-	MaxDuration *int   // Optional maximum duration in seconds
+	URL         string `json:"url"`                    // URL to download from
+	Format      string `json:"format,omitempty"`       // Desired format (e.g., "mp3", "mp4")
+	Quality     string `json:"quality,omitempty"`      // Desired quality (e.g., "high", "medium", "low")
+	MaxDuration *int   `json:"max_duration,omitempty"` // Optional maximum duration in seconds
 }
 
 // DownloadResult represents the result of a download operation.
 type DownloadResult struct {
-	FilePath string        // Path to the downloaded file
-	Metadata *SongMetadata // Metadata about the downloaded content
-	Duration int           // Duration in seconds
-	FileSize int64         // File size in bytes
+	FilePath string        `json:"file_path"`          // Path to the downloaded file
+	Metadata *SongMetadata `json:"metadata,omitempty"` // Metadata about the downloaded content
+	Duration int           `json:"duration"`           // Duration in seconds
+	FileSize int64         `json:"file_size"`          // File size in bytes
 }
 
 // SongMetadata represents metadata about a song.
 type SongMetadata struct {
-	Title        string  // Song title
-	Artist       *string // Artist name (optional)
-	Album        *string // Album name (optional)
-	Duration     *int    // Duration in seconds (optional)
-	ThumbnailURL *string // Thumbnail image URL (optional)
+	Title        string  `json:"title"`                   // Song title
+	Artist       *string `json:"artist,omitempty"`        // Artist name (optional)
+	Album        *string `json:"album,omitempty"`         // Album name (optional)
+	Duration     *int    `json:"duration,omitempty"`      // Duration in seconds (optional)
+	ThumbnailURL *string `json:"thumbnail_url,omitempty"` // Thumbnail image URL (optional)
 }
 
 // ContentDownloader is the interface that content download plugins must implement.
@@ -64,6 +64,24 @@ type ContentDownloader interface {
 	SupportedDomains() []string
 }
 
+// ProgressDownloader is the interface of content download plugins that
+// report the progress of their downloads. It may be implemented instead
+// of ContentDownloader; the download command then calls
+// DownloadWithProgress.
+type ProgressDownloader interface {
+	Plugin
+
+	// DownloadWithProgress downloads content from the given URL, reporting
+	// its progress to progress. Returns the download result or an error.
+	DownloadWithProgress(ctx *Context, req *DownloadRequest, progress ProgressReporter) (*DownloadResult, error)
+
+	// CanHandle checks if this plugin can handle the given URL.
+	CanHandle(url string) bool
+
+	// SupportedDomains returns a list of domains this plugin can handle.
+	SupportedDomains() []string
+}
+
 // MetadataResolverPlugin is a base plugin for metadata resolution plugins.
 // Embed this in your plugin to get default implementations.
 type MetadataResolverPlugin struct {
@@ -79,28 +97,28 @@ func NewMetadataResolverPlugin() *MetadataResolverPlugin {
 
 // ResolveURLRequest represents a request to resolve metadata from a URL.
 type ResolveURLRequest struct {
-	URL string // URL to resolve
+	URL string `json:"url"` // URL to resolve
 }
 
 // ResolveResult represents the result of a metadata resolution operation.
 type ResolveResult struct {
-	Metadata    *SongMetadata // Resolved metadata
-	DownloadURL *string       // Optional direct download URL
-	StreamURL   *string       // Optional streaming URL
+	Metadata    *SongMetadata `json:"metadata,omitempty"`     // Resolved metadata
+	DownloadURL *string       `json:"download_url,omitempty"` // Optional direct download URL
+	StreamURL   *string       `json:"stream_url,omitempty"`   // Optional streaming URL
 }
 
 // SearchRequest represents a request to search for content.
 type SearchRequest struct {
-	Query string // Search query
-	Limit *int   // Optional limit on number of results
+	Query string `json:"query"`           // Search query
+	Limit *int   `json:"limit,omitempty"` // Optional limit on number of results
 }
 
 // SearchResult represents a single search result.
 type SearchResult struct {
-	Metadata    *SongMetadata // Metadata about the content
-	URL         string        // URL to the content
-	DownloadURL *string       // Optional direct download URL
-	StreamURL   *string       // Optional streaming URL
+	Metadata    *SongMetadata `json:"metadata,omitempty"`     // Metadata about the content
+	URL         string        `json:"url"`                    // URL to the content
+	DownloadURL *string       `json:"download_url,omitempty"` // Optional direct download URL
+	StreamURL   *string       `json:"stream_url,omitempty"`   // Optional streaming URL
 }
 
 // MetadataResolver is the interface that metadata resolver plugins must implement.
@@ -136,8 +154,8 @@ func NewStorageProviderPlugin() *StorageProviderPlugin {
 
 // UploadAudioRequest represents a request to upload an audio file (FLAC).
 type UploadAudioRequest struct {
-	FilePath     string // Path to the FLAC file
-	BaseFilename string
+	FilePath     string `json:"file_path"` // Path to the FLAC file
+	BaseFilename string `json:"base_filename"`
 }
 
 // StorageProvider is the interface that storage provider plugins must implement.
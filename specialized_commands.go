@@ -0,0 +1,301 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+// Standard commands through which the host calls specialized plugins.
+//
+// Plugins implementing ContentDownloader (or ProgressDownloader),
+// MetadataResolver or StorageProvider on top of BasePlugin (for example by
+// embedding NewContentDownloaderPlugin())
+// get these commands registered automatically when the server starts, unless
+// the plugin registered a command with the same name itself.
+//
+// Arguments are passed either as a single JSON object or positionally in the
+// order listed. Results are JSON objects:
+//
+//	download           {"url", "format"?, "quality"?, "max_duration"?}
+//	                   -> {"file_path", "metadata"?, "duration", "file_size"}
+//	can_handle         {"url"} -> {"can_handle"}
+//	supported_domains  {} -> {"domains"}
+//	resolve_url        {"url"} -> {"metadata"?, "download_url"?, "stream_url"?}
+//	search             {"query", "limit"?}
+//	                   -> [{"metadata"?, "url", "download_url"?, "stream_url"?}]
+//	upload_audio       {"file_path", "base_filename"} -> {"cdn_url"}
+//	get_file_size_mb   {"cdn_url"} -> {"size_mb"}
+//	delete_audio       {"cdn_url"} -> {"deleted"}
+//
+// "metadata" objects have the fields "title", "artist"?, "album"?,
+// "duration"? and "thumbnail_url"?.
+const (
+	CommandDownload         = "download"
+	CommandCanHandle        = "can_handle"
+	CommandSupportedDomains = "supported_domains"
+	CommandResolveURL       = "resolve_url"
+	CommandSearch           = "search"
+	CommandUploadAudio      = "upload_audio"
+	CommandGetFileSizeMB    = "get_file_size_mb"
+	CommandDeleteAudio      = "delete_audio"
+)
+
+// URLArgs holds the arguments of commands taking a single URL.
+type URLArgs struct {
+	URL string `json:"url"`
+}
+
+// CanHandleResult is the result of the can_handle command.
+type CanHandleResult struct {
+	CanHandle bool `json:"can_handle"`
+}
+
+// SupportedDomainsResult is the result of the supported_domains command.
+type SupportedDomainsResult struct {
+	Domains []string `json:"domains"`
+}
+
+// CDNURLArgs holds the arguments of commands taking a single CDN URL.
+type CDNURLArgs struct {
+	CDNURL string `json:"cdn_url"`
+}
+
+// UploadAudioResult is the result of the upload_audio command.
+type UploadAudioResult struct {
+	CDNURL string `json:"cdn_url"`
+}
+
+// FileSizeResult is the result of the get_file_size_mb command.
+type FileSizeResult struct {
+	SizeMB float64 `json:"size_mb"`
+}
+
+// DeleteAudioResult is the result of the delete_audio command.
+type DeleteAudioResult struct {
+	Deleted bool `json:"deleted"`
+}
+
+// commandRegistry is implemented by plugins that route commands through a
+// CommandRouter, such as plugins embedding BasePlugin.
+type commandRegistry interface {
+	RegisterCommand(name string, handler CommandHandler, opts ...CommandOption) error
+	HasCommand(name string) bool
+}
+
+// domainMatcher is the part shared by ContentDownloader and MetadataResolver.
+type domainMatcher interface {
+	CanHandle(url string) bool
+	SupportedDomains() []string
+}
+
+// registerSpecializedCommands registers the standard commands of the
+// specialized interfaces the plugin implements.
+func registerSpecializedCommands(plugin Plugin) error {
+	registry, ok := plugin.(commandRegistry)
+	if !ok {
+		return nil
+	}
+
+	var commands []specializedCommand
+	if matcher, ok := plugin.(domainMatcher); ok {
+		commands = append(commands, domainMatcherCommands(matcher)...)
+	}
+	if downloader, ok := plugin.(ProgressDownloader); ok {
+		commands = append(commands, contentDownloaderCommands(downloader.DownloadWithProgress)...)
+	} else if downloader, ok := plugin.(ContentDownloader); ok {
+		commands = append(commands, contentDownloaderCommands(func(ctx *Context, req *DownloadRequest, _ ProgressReporter) (*DownloadResult, error) {
+			return downloader.Download(ctx, req)
+		})...)
+	}
+	if resolver, ok := plugin.(MetadataResolver); ok {
+		commands = append(commands, metadataResolverCommands(resolver)...)
+	}
+	if provider, ok := plugin.(StorageProvider); ok {
+		commands = append(commands, storageProviderCommands(provider)...)
+	}
+
+	for _, cmd := range commands {
+		if registry.HasCommand(cmd.name) {
+			continue
+		}
+		if err := registry.RegisterCommand(cmd.name, cmd.handler, cmd.opts...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// specializedCommand is a standard command to register.
+type specializedCommand struct {
+	name    string
+	handler CommandHandler
+	opts    []CommandOption
+}
+
+// domainMatcherCommands returns the commands shared by downloaders and resolvers.
+func domainMatcherCommands(m domainMatcher) []specializedCommand {
+	return []specializedCommand{
+		{
+			name: CommandCanHandle,
+			handler: func(ctx *Context, args *URLArgs) (*CanHandleResult, error) {
+				return &CanHandleResult{CanHandle: m.CanHandle(args.URL)}, nil
+			},
+			opts: []CommandOption{
+				WithDescription("Check if the plugin can handle a URL"),
+				WithParameters(Param("url", ParamTypeString, "URL to check")),
+				WithReturnType("CanHandleResult", map[string]ParamType{"can_handle": ParamTypeBool}),
+			},
+		},
+		{
+			name: CommandSupportedDomains,
+			handler: func(ctx *Context) (*SupportedDomainsResult, error) {
+				domains := m.SupportedDomains()
+				if domains == nil {
+					domains = []string{}
+				}
+				return &SupportedDomainsResult{Domains: domains}, nil
+			},
+			opts: []CommandOption{
+				WithDescription("List the domains the plugin can handle"),
+				WithReturnType("SupportedDomainsResult", map[string]ParamType{"domains": ParamTypeArray}),
+			},
+		},
+	}
+}
+
+// downloadFunc downloads content, reporting its progress to progress.
+type downloadFunc func(ctx *Context, req *DownloadRequest, progress ProgressReporter) (*DownloadResult, error)
+
+// contentDownloaderCommands returns the commands of a ContentDownloader or
+// ProgressDownloader downloading with download.
+func contentDownloaderCommands(download downloadFunc) []specializedCommand {
+	return []specializedCommand{
+		{
+			name: CommandDownload,
+			handler: func(ctx *Context, req *DownloadRequest) (*DownloadResult, error) {
+				return download(ctx, req, ctx.ProgressReporter())
+			},
+			opts: []CommandOption{
+				WithDescription("Download content from a URL"),
+				WithParameters(
+					Param("url", ParamTypeString, "URL to download from"),
+					Param("format", ParamTypeString, "Desired format", Optional()),
+					Param("quality", ParamTypeString, "Desired quality", Optional()),
+					Param("max_duration", ParamTypeInt, "Maximum duration in seconds", Optional()),
+				),
+				WithReturnType("DownloadResult", map[string]ParamType{
+					"file_path": ParamTypeString,
+					"metadata":  ParamTypeObject,
+					"duration":  ParamTypeInt,
+					"file_size": ParamTypeInt,
+				}),
+			},
+		},
+	}
+}
+
+// metadataResolverCommands returns the commands of a MetadataResolver.
+func metadataResolverCommands(r MetadataResolver) []specializedCommand {
+	return []specializedCommand{
+		{
+			name: CommandResolveURL,
+			handler: func(ctx *Context, req *ResolveURLRequest) (*ResolveResult, error) {
+				return r.ResolveURL(ctx, req)
+			},
+			opts: []CommandOption{
+				WithDescription("Resolve metadata for a URL"),
+				WithParameters(Param("url", ParamTypeString, "URL to resolve")),
+				WithReturnType("ResolveResult", map[string]ParamType{
+					"metadata":     ParamTypeObject,
+					"download_url": ParamTypeString,
+					"stream_url":   ParamTypeString,
+				}),
+			},
+		},
+		{
+			name: CommandSearch,
+			handler: func(ctx *Context, req *SearchRequest) ([]*SearchResult, error) {
+				results, err := r.Search(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				if results == nil {
+					results = []*SearchResult{}
+				}
+				return results, nil
+			},
+			opts: []CommandOption{
+				WithDescription("Search for content"),
+				WithParameters(
+					Param("query", ParamTypeString, "Search query"),
+					Param("limit", ParamTypeInt, "Maximum number of results", Optional()),
+				),
+				WithReturnType("[]SearchResult", map[string]ParamType{
+					"metadata":     ParamTypeObject,
+					"url":          ParamTypeString,
+					"download_url": ParamTypeString,
+					"stream_url":   ParamTypeString,
+				}),
+			},
+		},
+	}
+}
+
+// storageProviderCommands returns the commands of a StorageProvider.
+func storageProviderCommands(p StorageProvider) []specializedCommand {
+	return []specializedCommand{
+		{
+			name: CommandUploadAudio,
+			handler: func(ctx *Context, req *UploadAudioRequest) (*UploadAudioResult, error) {
+				cdnURL, err := p.UploadAudio(ctx, req)
+				if err != nil {
+					return nil, err
+				}
+				return &UploadAudioResult{CDNURL: cdnURL}, nil
+			},
+			opts: []CommandOption{
+				WithDescription("Upload an audio file (FLAC)"),
+				WithParameters(
+					Param("file_path", ParamTypeString, "Path to the FLAC file"),
+					Param("base_filename", ParamTypeString, "Base filename to store the file under"),
+				),
+				WithReturnType("UploadAudioResult", map[string]ParamType{"cdn_url": ParamTypeString}),
+			},
+		},
+		{
+			name: CommandGetFileSizeMB,
+			handler: func(ctx *Context, args *CDNURLArgs) (*FileSizeResult, error) {
+				size, err := p.GetFileSizeMB(ctx, args.CDNURL)
+				if err != nil {
+					return nil, err
+				}
+				return &FileSizeResult{SizeMB: size}, nil
+			},
+			opts: []CommandOption{
+				WithDescription("Get the size of an audio file in MB"),
+				WithParameters(Param("cdn_url", ParamTypeString, "CDN URL of the file")),
+				WithReturnType("FileSizeResult", map[string]ParamType{"size_mb": ParamTypeFloat}),
+			},
+		},
+		{
+			name: CommandDeleteAudio,
+			handler: func(ctx *Context, args *CDNURLArgs) (*DeleteAudioResult, error) {
+				if err := p.DeleteAudio(ctx, args.CDNURL); err != nil {
+					return nil, err
+				}
+				return &DeleteAudioResult{Deleted: true}, nil
+			},
+			opts: []CommandOption{
+				WithDescription("Delete an audio file from storage"),
+				WithParameters(Param("cdn_url", ParamTypeString, "CDN URL of the file")),
+				WithReturnType("DeleteAudioResult", map[string]ParamType{"deleted": ParamTypeBool}),
+			},
+		},
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testDownloader struct {
+	*ContentDownloaderPlugin
+}
+
+func (d *testDownloader) Download(ctx *Context, req *DownloadRequest) (*DownloadResult, error) {
+	if req.URL == "" {
+		return nil, InvalidArgument("url is required")
+	}
+	return &DownloadResult{
+		FilePath: "/tmp/" + req.Format,
+		Metadata: &SongMetadata{Title: req.URL},
+		Duration: *req.MaxDuration,
+	}, nil
+}
+
+func (d *testDownloader) CanHandle(url string) bool {
+	return strings.HasPrefix(url, "https://example.com/")
+}
+
+func (d *testDownloader) SupportedDomains() []string {
+	return nil
+}
+
+// testProgressDownloader reports the progress of its downloads.
+type testProgressDownloader struct {
+	*ContentDownloaderPlugin
+}
+
+func (d *testProgressDownloader) DownloadWithProgress(ctx *Context, req *DownloadRequest, progress ProgressReporter) (*DownloadResult, error) {
+	progress.Report(50, "downloading")
+	return &DownloadResult{FilePath: "/tmp/" + req.URL}, nil
+}
+
+func (d *testProgressDownloader) CanHandle(url string) bool {
+	return true
+}
+
+func (d *testProgressDownloader) SupportedDomains() []string {
+	return nil
+}
+
+type testResolver struct {
+	*MetadataResolverPlugin
+}
+
+func (r *testResolver) ResolveURL(ctx *Context, req *ResolveURLRequest) (*ResolveResult, error) {
+	stream := req.URL + "/stream"
+	return &ResolveResult{Metadata: &SongMetadata{Title: "Song"}, StreamURL: &stream}, nil
+}
+
+func (r *testResolver) Search(ctx *Context, req *SearchRequest) ([]*SearchResult, error) {
+	if req.Query == "nothing" {
+		return nil, nil
+	}
+	results := make([]*SearchResult, *req.Limit)
+	for i := range results {
+		results[i] = &SearchResult{URL: req.Query}
+	}
+	return results, nil
+}
+
+func (r *testResolver) CanHandle(url string) bool {
+	return true
+}
+
+func (r *testResolver) SupportedDomains() []string {
+	return []string{"example.com"}
+}
+
+type testStorage struct {
+	*StorageProviderPlugin
+	deleted []string
+}
+
+func (s *testStorage) UploadAudio(ctx *Context, req *UploadAudioRequest) (string, error) {
+	return "https://cdn.example.com/" + req.BaseFilename + ".flac", nil
+}
+
+func (s *testStorage) GetFileSizeMB(ctx *Context, cdnURL string) (float64, error) {
+	return 4.5, nil
+}
+
+func (s *testStorage) DeleteAudio(ctx *Context, cdnURL string) error {
+	s.deleted = append(s.deleted, cdnURL)
+	return nil
+}
+
+func TestContentDownloaderCommands(t *testing.T) {
+	server := newTestServer(t, &testDownloader{NewContentDownloaderPlugin()})
+	target := newTestTarget()
+
+	var result DownloadResult
+	target.run(t, server, &result, CommandDownload, "https://example.com/a", "mp3", "high", 90)
+	if result.FilePath != "/tmp/mp3" || result.Duration != 90 || result.Metadata.Title != "https://example.com/a" {
+		t.Fatalf("positional download returned %+v", result)
+	}
+	target.run(t, server, &result, CommandDownload, map[string]interface{}{"url": "https://example.com/b", "format": "flac", "max_duration": 10})
+	if result.FilePath != "/tmp/flac" || result.Duration != 10 {
+		t.Fatalf("object download returned %+v", result)
+	}
+
+	var canHandle CanHandleResult
+	target.run(t, server, &canHandle, CommandCanHandle, "https://example.com/a")
+	if !canHandle.CanHandle {
+		t.Fatal("can_handle rejected a supported URL")
+	}
+
+	var domains SupportedDomainsResult
+	target.run(t, server, &domains, CommandSupportedDomains)
+	if domains.Domains == nil || len(domains.Domains) != 0 {
+		t.Fatalf("supported_domains returned %#v, want an empty list", domains.Domains)
+	}
+}
+
+func TestMetadataResolverCommands(t *testing.T) {
+	server := newTestServer(t, &testResolver{NewMetadataResolverPlugin()})
+	target := newTestTarget()
+
+	var resolved ResolveResult
+	target.run(t, server, &resolved, CommandResolveURL, map[string]interface{}{"url": "https://example.com/a"})
+	if resolved.Metadata.Title != "Song" || *resolved.StreamURL != "https://example.com/a/stream" {
+		t.Fatalf("resolve_url returned %+v", resolved)
+	}
+
+	var results []*SearchResult
+	target.run(t, server, &results, CommandSearch, "query", 2)
+	if len(results) != 2 || results[0].URL != "query" {
+		t.Fatalf("search returned %+v", results)
+	}
+	target.run(t, server, &results, CommandSearch, "nothing")
+	if results == nil || len(results) != 0 {
+		t.Fatalf("empty search returned %#v, want an empty list", results)
+	}
+}
+
+func TestStorageProviderCommands(t *testing.T) {
+	plugin := &testStorage{StorageProviderPlugin: NewStorageProviderPlugin()}
+	server := newTestServer(t, plugin)
+	target := newTestTarget()
+
+	var uploaded UploadAudioResult
+	target.run(t, server, &uploaded, CommandUploadAudio, "/tmp/song.flac", "song")
+	if uploaded.CDNURL != "https://cdn.example.com/song.flac" {
+		t.Fatalf("upload_audio returned %+v", uploaded)
+	}
+
+	var size FileSizeResult
+	target.run(t, server, &size, CommandGetFileSizeMB, uploaded.CDNURL)
+	if size.SizeMB != 4.5 {
+		t.Fatalf("get_file_size_mb returned %+v", size)
+	}
+
+	var deleted DeleteAudioResult
+	target.run(t, server, &deleted, CommandDeleteAudio, map[string]interface{}{"cdn_url": uploaded.CDNURL})
+	if !deleted.Deleted || !reflect.DeepEqual(plugin.deleted, []string{uploaded.CDNURL}) {
+		t.Fatalf("delete_audio returned %+v, deleted %v", deleted, plugin.deleted)
+	}
+}
+
+func TestSpecializedCommandsKeepPluginCommands(t *testing.T) {
+	plugin := &testDownloader{NewContentDownloaderPlugin()}
+	must(t, plugin.RegisterCommand(CommandCanHandle, func(ctx *Context) (interface{}, error) {
+		return "custom", nil
+	}))
+	server := newTestServer(t, plugin)
+
+	var result string
+	newTestTarget().run(t, server, &result, CommandCanHandle)
+	if result != "custom" {
+		t.Fatalf("can_handle returned %q, want the plugin's own command", result)
+	}
+}
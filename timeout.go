@@ -0,0 +1,58 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// WithTimeout sets the timeout applied to the command when the host does
+// not give one.
+func WithTimeout(d time.Duration) CommandOption {
+	return func(m *CommandMetadata) {
+		m.TimeoutMs = d.Milliseconds()
+	}
+}
+
+// WithMaxTimeout caps the timeout of the command, including timeouts given
+// by the host. Without WithTimeout, it also applies when the host gives no timeout.
+func WithMaxTimeout(d time.Duration) CommandOption {
+	return func(m *CommandMetadata) {
+		m.MaxTimeoutMs = d.Milliseconds()
+	}
+}
+
+// commandTimeout returns the timeout of a call given the timeout requested
+// by the host, or zero if the call is unbounded.
+func commandTimeout(requested time.Duration, cmd CommandMetadata) time.Duration {
+	timeout := requested
+	if timeout <= 0 {
+		timeout = time.Duration(cmd.TimeoutMs) * time.Millisecond
+	}
+
+	maxTimeout := time.Duration(cmd.MaxTimeoutMs) * time.Millisecond
+	if maxTimeout > 0 && (timeout <= 0 || timeout > maxTimeout) {
+		timeout = maxTimeout
+	}
+	return timeout
+}
+
+// deadlineError reports a failure as DEADLINE_EXCEEDED if ctx's deadline
+// expired, whatever error the handler returned. Panics keep their code.
+func deadlineError(ctx context.Context, err error) error {
+	if err == nil || isPanic(err) || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	return DeadlineExceeded("deadline exceeded").WithCause(err)
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCommandTimeout(t *testing.T) {
+	tests := []struct {
+		name      string
+		requested time.Duration
+		cmd       CommandMetadata
+		want      time.Duration
+	}{
+		{name: "unbounded"},
+		{name: "host timeout", requested: time.Second, want: time.Second},
+		{name: "default timeout", cmd: CommandMetadata{TimeoutMs: 500}, want: 500 * time.Millisecond},
+		{name: "host timeout over default", requested: time.Second, cmd: CommandMetadata{TimeoutMs: 500}, want: time.Second},
+		{name: "host timeout capped", requested: time.Minute, cmd: CommandMetadata{MaxTimeoutMs: 2000}, want: 2 * time.Second},
+		{name: "host timeout under max", requested: time.Second, cmd: CommandMetadata{MaxTimeoutMs: 2000}, want: time.Second},
+		{name: "max without default", cmd: CommandMetadata{MaxTimeoutMs: 2000}, want: 2 * time.Second},
+		{name: "default capped", cmd: CommandMetadata{TimeoutMs: 5000, MaxTimeoutMs: 2000}, want: 2 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := commandTimeout(tt.requested, tt.cmd); got != tt.want {
+				t.Fatalf("commandTimeout() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// waitForDeadline blocks until its context is done and returns its error.
+func waitForDeadline(ctx *Context) (interface{}, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestCommandDefaultTimeoutExpires(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("hang", waitForDeadline, WithTimeout(20*time.Millisecond)))
+	server := newTestServer(t, plugin)
+
+	perr := newTestTarget().execute(t, server, testContext(t), nil, "hang")
+	if perr.GetCode() != string(CodeDeadlineExceeded) {
+		t.Fatalf("error = %v, want %s", perr, CodeDeadlineExceeded)
+	}
+}
+
+func TestCommandMaxTimeoutCapsHostTimeout(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("hang", waitForDeadline, WithMaxTimeout(20*time.Millisecond)))
+	server := newTestServer(t, plugin)
+
+	req := newTestTarget().request(t, "hang")
+	req.TimeoutMs = time.Hour.Milliseconds()
+	start := time.Now()
+	resp, err := server.ExecuteCommand(testContext(t), req)
+	must(t, err)
+	if code := resp.GetError().GetCode(); code != string(CodeDeadlineExceeded) {
+		t.Fatalf("error code = %s, want %s", code, CodeDeadlineExceeded)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("command ran %v, want it capped by the maximum timeout", elapsed)
+	}
+}
+
+func TestCommandTimeoutsInMetadata(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("hang", waitForDeadline,
+		WithTimeout(10*time.Second),
+		WithMaxTimeout(time.Minute),
+	))
+	server := newTestServer(t, plugin)
+
+	var metadata CommandMetadata
+	newTestTarget().run(t, server, &metadata, DescribeCommand, "hang")
+	if metadata.TimeoutMs != 10000 || metadata.MaxTimeoutMs != 60000 {
+		t.Fatalf("metadata timeouts = %d, %d, want 10000, 60000", metadata.TimeoutMs, metadata.MaxTimeoutMs)
+	}
+}
+
+func TestCommandWithoutTimeoutIsUnbounded(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("deadline", func(ctx *Context) (interface{}, error) {
+		_, ok := ctx.Deadline()
+		return ok, nil
+	}))
+	server := newTestServer(t, plugin)
+
+	var bounded bool
+	newTestTarget().run(t, server, &bounded, "deadline")
+	if bounded {
+		t.Fatal("command without timeouts has a deadline")
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"strings"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// tracerName is the instrumentation name of the SDK's spans.
+const tracerName = "github.com/wabisaby/wabisaby-plugin-sdk"
+
+// Span attribute keys set by the SDK.
+const (
+	attrCommand        = attribute.Key("wabisaby.command")
+	attrTenantID       = attribute.Key("wabisaby.tenant_id")
+	attrPluginID       = attribute.Key("wabisaby.plugin_id")
+	attrRequestID      = attribute.Key("wabisaby.request_id")
+	attrErrorCode      = attribute.Key("wabisaby.error.code")
+	attrErrorRetryable = attribute.Key("wabisaby.error.retryable")
+	attrRPCSystem      = attribute.Key("rpc.system")
+	attrRPCService     = attribute.Key("rpc.service")
+	attrRPCMethod      = attribute.Key("rpc.method")
+)
+
+// tracing creates the spans of a server and propagates trace context.
+type tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+// newTracing creates the tracing of a server. A nil provider disables
+// tracing; a nil propagator defaults to W3C trace context and baggage when
+// tracing is enabled.
+func newTracing(provider trace.TracerProvider, propagator propagation.TextMapPropagator) *tracing {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+		if propagator == nil {
+			propagator = propagation.NewCompositeTextMapPropagator()
+		}
+	}
+	if propagator == nil {
+		propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+	}
+	return &tracing{
+		tracer:     provider.Tracer(tracerName),
+		propagator: propagator,
+	}
+}
+
+// startCommand starts the server span of a command execution, continuing
+// the trace propagated by the host in the incoming gRPC metadata.
+func (t *tracing) startCommand(ctx context.Context, req *pluginpb.ExecuteCommandRequest) (context.Context, trace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = t.propagator.Extract(ctx, metadataCarrier(md))
+	ctx, request := withRequestInfo(ctx)
+
+	attrs := []attribute.KeyValue{
+		attrCommand.String(req.Command),
+		attrTenantID.String(req.TenantId),
+		attrPluginID.String(req.PluginId),
+		attrRequestID.String(request.RequestID),
+	}
+
+	return t.tracer.Start(ctx, "ExecuteCommand "+req.Command,
+		trace.WithSpanKind(trace.SpanKindServer),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endCommand records the outcome of a command execution on its span.
+func (t *tracing) endCommand(span trace.Span, resp *pluginpb.ExecuteCommandResponse) {
+	recordPluginError(span, resp.GetError())
+	span.End()
+}
+
+// unaryClientInterceptor returns a client interceptor creating a child span
+// for every capability call and injecting the trace context into its
+// outgoing gRPC metadata.
+func (t *tracing) unaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		service, name := splitMethod(method)
+		ctx, span := t.tracer.Start(ctx, name,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				attrRPCSystem.String("grpc"),
+				attrRPCService.String(service),
+				attrRPCMethod.String(name),
+			),
+		)
+		defer span.End()
+
+		md, _ := metadata.FromOutgoingContext(ctx)
+		md = md.Copy()
+		t.propagator.Inject(ctx, metadataCarrier(md))
+		ctx = metadata.NewOutgoingContext(ctx, md)
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		if err != nil {
+			span.SetStatus(codes.Error, status.Convert(err).Message())
+			span.SetAttributes(attrErrorCode.String(status.Code(err).String()))
+			return err
+		}
+
+		// Capabilities report failures in the response
+		if resp, ok := reply.(interface{ GetError() *pluginpb.PluginError }); ok {
+			recordPluginError(span, resp.GetError())
+		}
+		return nil
+	}
+}
+
+// recordPluginError sets the span status from a PluginError, if any.
+func recordPluginError(span trace.Span, pluginErr *pluginpb.PluginError) {
+	if pluginErr == nil {
+		span.SetStatus(codes.Ok, "")
+		return
+	}
+	retryable, _ := errorDetails(pluginErr)
+	span.SetStatus(codes.Error, pluginErr.Message)
+	span.SetAttributes(
+		attrErrorCode.String(pluginErr.Code),
+		attrErrorRetryable.Bool(retryable),
+	)
+}
+
+// splitMethod splits a full gRPC method name into its service and method.
+func splitMethod(fullMethod string) (string, string) {
+	fullMethod = strings.TrimPrefix(fullMethod, "/")
+	if i := strings.LastIndex(fullMethod, "/"); i >= 0 {
+		return fullMethod[:i], fullMethod[i+1:]
+	}
+	return "", fullMethod
+}
+
+// metadataCarrier adapts gRPC metadata to a propagation.TextMapCarrier.
+type metadataCarrier metadata.MD
+
+// Get implements propagation.TextMapCarrier.
+func (c metadataCarrier) Get(key string) string {
+	return firstMetadata(metadata.MD(c), key)
+}
+
+// Set implements propagation.TextMapCarrier.
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// Keys implements propagation.TextMapCarrier.
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for key := range c {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// WithTracerProvider enables OpenTelemetry tracing with provider. The SDK
+// creates a span per command execution and a child span per capability call.
+func WithTracerProvider(provider trace.TracerProvider) ServeOption {
+	return func(c *serveConfig) {
+		c.tracerProvider = provider
+	}
+}
+
+// WithTextMapPropagator sets the propagator reading trace context from the
+// host and injecting it into capability calls. Defaults to W3C trace context
+// and baggage when tracing is enabled.
+func WithTextMapPropagator(propagator propagation.TextMapPropagator) ServeOption {
+	return func(c *serveConfig) {
+		c.propagator = propagator
+	}
+}
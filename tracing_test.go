@@ -0,0 +1,223 @@
+// Copyright (c) 2026 WabiSaby
+// All rights reserved.
+//
+// This source code is proprietary and confidential. Unauthorized copying,
+// modification, distribution, or use of this software, via any medium is
+// strictly prohibited without the express written permission of WabiSaby.
+//
+// This software contains confidential and proprietary information of
+// WabiSaby and its licensors. Use, disclosure, or reproduction
+// is prohibited without the prior express written permission of WabiSaby.
+
+package sdk
+
+import (
+	"context"
+	"testing"
+
+	pluginpb "github.com/wabisaby/wabisaby-protos-go/go/plugin"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	testTraceID     = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testTraceParent = "00-" + testTraceID + "-00f067aa0ba902b7-01"
+)
+
+// tracedServer creates a test server recording its spans in memory.
+// Capability calls go through the tracing client interceptor.
+func tracedServer(t *testing.T, plugin Plugin) (*Server, *tracetest.InMemoryExporter) {
+	t.Helper()
+
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	t.Cleanup(func() { _ = provider.Shutdown(context.Background()) })
+
+	server := newTestServer(t, plugin, WithTracerProvider(provider))
+	server.capabilitiesClient = tracedClient{
+		PluginCapabilitiesServiceClient: server.capabilitiesClient,
+		interceptor:                     server.tracing.unaryClientInterceptor(),
+	}
+	return server, exporter
+}
+
+// tracedClient runs StorageGet and StorageSet calls through a client interceptor.
+type tracedClient struct {
+	pluginpb.PluginCapabilitiesServiceClient
+	interceptor grpc.UnaryClientInterceptor
+}
+
+func (c tracedClient) StorageGet(ctx context.Context, in *pluginpb.StorageGetRequest, opts ...grpc.CallOption) (*pluginpb.StorageGetResponse, error) {
+	var resp *pluginpb.StorageGetResponse
+	err := c.interceptor(ctx, "/wabisaby.plugin.PluginCapabilitiesService/StorageGet", in, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			var err error
+			resp, err = c.PluginCapabilitiesServiceClient.StorageGet(ctx, in)
+			return err
+		}, opts...)
+	return resp, err
+}
+
+func (c tracedClient) StorageSet(ctx context.Context, in *pluginpb.StorageSetRequest, opts ...grpc.CallOption) (*pluginpb.StorageSetResponse, error) {
+	var resp *pluginpb.StorageSetResponse
+	err := c.interceptor(ctx, "/wabisaby.plugin.PluginCapabilitiesService/StorageSet", in, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			var err error
+			resp, err = c.PluginCapabilitiesServiceClient.StorageSet(ctx, in)
+			return err
+		}, opts...)
+	return resp, err
+}
+
+// spanNamed returns the ended span with name.
+func spanNamed(t *testing.T, exporter *tracetest.InMemoryExporter, name string) tracetest.SpanStub {
+	t.Helper()
+
+	for _, span := range exporter.GetSpans() {
+		if span.Name == name {
+			return span
+		}
+	}
+	t.Fatalf("no span named %q in %d spans", name, len(exporter.GetSpans()))
+	return tracetest.SpanStub{}
+}
+
+// spanAttribute returns the value of an attribute of span.
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) (attribute.Value, bool) {
+	for _, attr := range span.Attributes {
+		if attr.Key == key {
+			return attr.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracingCommandSpanContinuesHostTrace(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("remember", func(ctx *Context) (interface{}, error) {
+		return nil, ctx.Storage.Set(ctx, "key", []byte("value"))
+	}))
+	server, exporter := tracedServer(t, plugin)
+	target := newTestTarget()
+
+	ctx := metadata.NewIncomingContext(testContext(t), metadata.Pairs(
+		MetadataTraceParent, testTraceParent,
+		MetadataRequestID, "req-1",
+	))
+	if perr := target.execute(t, server, ctx, nil, "remember"); perr != nil {
+		t.Fatalf("remember failed: %v", perr)
+	}
+
+	command := spanNamed(t, exporter, "ExecuteCommand remember")
+	if command.SpanKind != trace.SpanKindServer {
+		t.Errorf("command span kind = %v, want server", command.SpanKind)
+	}
+	if got := command.SpanContext.TraceID().String(); got != testTraceID {
+		t.Errorf("command trace ID = %s, want the host's %s", got, testTraceID)
+	}
+	if command.Status.Code != otelcodes.Ok {
+		t.Errorf("command status = %v, want Ok", command.Status)
+	}
+	if value, _ := spanAttribute(command, attrTenantID); value.AsString() != target.tenantID.String() {
+		t.Errorf("tenant attribute = %q, want %s", value.AsString(), target.tenantID)
+	}
+	if value, _ := spanAttribute(command, attrRequestID); value.AsString() != "req-1" {
+		t.Errorf("request ID attribute = %q, want req-1", value.AsString())
+	}
+
+	capability := spanNamed(t, exporter, "StorageSet")
+	if capability.SpanKind != trace.SpanKindClient {
+		t.Errorf("capability span kind = %v, want client", capability.SpanKind)
+	}
+	if capability.Parent.SpanID() != command.SpanContext.SpanID() {
+		t.Errorf("capability span parent = %s, want the command span %s", capability.Parent.SpanID(), command.SpanContext.SpanID())
+	}
+}
+
+func TestTracingCommandSpanRecordsGeneratedRequestID(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("request", func(ctx *Context) (interface{}, error) {
+		return ctx.Request.RequestID, nil
+	}))
+	server, exporter := tracedServer(t, plugin)
+
+	var requestID string
+	newTestTarget().run(t, server, &requestID, "request")
+
+	command := spanNamed(t, exporter, "ExecuteCommand request")
+	if value, _ := spanAttribute(command, attrRequestID); value.AsString() != requestID {
+		t.Fatalf("request ID attribute = %q, want the generated %q", value.AsString(), requestID)
+	}
+}
+
+func TestTracingCommandSpanRecordsError(t *testing.T) {
+	plugin := NewBasePlugin()
+	must(t, plugin.RegisterCommand("missing", func(ctx *Context) (interface{}, error) {
+		return nil, NotFound("song not found")
+	}))
+	server, exporter := tracedServer(t, plugin)
+
+	newTestTarget().execute(t, server, testContext(t), nil, "missing")
+
+	command := spanNamed(t, exporter, "ExecuteCommand missing")
+	if command.Status.Code != otelcodes.Error || command.Status.Description != "song not found" {
+		t.Fatalf("command status = %+v, want the error", command.Status)
+	}
+	if value, _ := spanAttribute(command, attrErrorCode); value.AsString() != string(CodeNotFound) {
+		t.Fatalf("error code attribute = %q, want %s", value.AsString(), CodeNotFound)
+	}
+}
+
+func TestTracingClientInterceptorPropagatesTraceContext(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracing := newTracing(provider, nil)
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataTraceParent, testTraceParent))
+	ctx, span := tracing.startCommand(ctx, &pluginpb.ExecuteCommandRequest{Command: "download"})
+	_, request := withRequestInfo(ctx)
+
+	var sent metadata.MD
+	err := tracing.unaryClientInterceptor()(ctx, "/wabisaby.plugin.PluginCapabilitiesService/SongGet", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			sent, _ = metadata.FromOutgoingContext(ctx)
+			return status.Error(codes.Unavailable, "capabilities down")
+		})
+	span.End()
+	if status.Code(err) != codes.Unavailable {
+		t.Fatalf("error = %v, want the invoker's error", err)
+	}
+
+	child := spanNamed(t, exporter, "SongGet")
+	traceParent := firstMetadata(sent, MetadataTraceParent)
+	if want := "00-" + testTraceID + "-" + child.SpanContext.SpanID().String() + "-01"; traceParent != want {
+		t.Errorf("sent traceparent = %q, want %q", traceParent, want)
+	}
+	if got := firstMetadata(sent, MetadataRequestID); got != request.RequestID {
+		t.Errorf("sent request ID = %q, want the call's %q kept", got, request.RequestID)
+	}
+	if child.Status.Code != otelcodes.Error || child.Status.Description != "capabilities down" {
+		t.Errorf("capability span status = %+v, want the error", child.Status)
+	}
+	if value, _ := spanAttribute(child, attrErrorCode); value.AsString() != codes.Unavailable.String() {
+		t.Errorf("error code attribute = %q, want %s", value.AsString(), codes.Unavailable)
+	}
+}
+
+func TestTracingDisabledByDefault(t *testing.T) {
+	server := newTestServer(t, NewBasePlugin())
+
+	_, span := server.tracing.startCommand(testContext(t), &pluginpb.ExecuteCommandRequest{Command: "noop"})
+	defer span.End()
+	if span.SpanContext().IsValid() {
+		t.Fatal("span recorded without a tracer provider")
+	}
+}
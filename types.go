@@ -43,7 +43,8 @@ type HTTPResponse = stub.HTTPResponse
 
 // ConfigAccessor provides typed access to plugin configuration.
 type ConfigAccessor struct {
-	data map[string]interface{}
+	data    map[string]interface{}
+	version string
 }
 
 // NewConfigAccessor creates a new config accessor.
@@ -51,6 +52,16 @@ func NewConfigAccessor(data map[string]interface{}) *ConfigAccessor {
 	return &ConfigAccessor{data: data}
 }
 
+// Version returns the version of the configuration, or an empty string if
+// the configuration was not provided by the host.
+// The version changes whenever the tenant's configuration changes.
+func (c *ConfigAccessor) Version() string {
+	if c == nil {
+		return ""
+	}
+	return c.version
+}
+
 // Get returns a raw config value.
 func (c *ConfigAccessor) Get(key string) interface{} {
 	if c == nil || c.data == nil {
@@ -141,7 +152,7 @@ func NewContextLogger(logger *stub.Logger, ctx *Context) *ContextLogger {
 
 // Info logs an info message with key-value pairs.
 func (l *ContextLogger) Info(msg string, keysAndValues ...interface{}) {
-	fields := toStringMap(keysAndValues...)
+	fields := l.fields(keysAndValues...)
 	if err := l.logger.Info(l.ctx, msg, fields); err != nil {
 		// Logging errors are non-critical, ignore
 		_ = err
@@ -150,7 +161,7 @@ func (l *ContextLogger) Info(msg string, keysAndValues ...interface{}) {
 
 // Debug logs a debug message with key-value pairs.
 func (l *ContextLogger) Debug(msg string, keysAndValues ...interface{}) {
-	fields := toStringMap(keysAndValues...)
+	fields := l.fields(keysAndValues...)
 	if err := l.logger.Debug(l.ctx, msg, fields); err != nil {
 		// Logging errors are non-critical, ignore
 		_ = err
@@ -159,7 +170,7 @@ func (l *ContextLogger) Debug(msg string, keysAndValues ...interface{}) {
 
 // Warn logs a warning message with key-value pairs.
 func (l *ContextLogger) Warn(msg string, keysAndValues ...interface{}) {
-	fields := toStringMap(keysAndValues...)
+	fields := l.fields(keysAndValues...)
 	if err := l.logger.Warn(l.ctx, msg, fields); err != nil {
 		// Logging errors are non-critical, ignore
 		_ = err
@@ -168,13 +179,29 @@ func (l *ContextLogger) Warn(msg string, keysAndValues ...interface{}) {
 
 // Error logs an error message with key-value pairs.
 func (l *ContextLogger) Error(msg string, keysAndValues ...interface{}) {
-	fields := toStringMap(keysAndValues...)
+	fields := l.fields(keysAndValues...)
 	if err := l.logger.Error(l.ctx, msg, fields); err != nil {
 		// Logging errors are non-critical, ignore
 		_ = err
 	}
 }
 
+// fields converts key-value pairs to log fields, adding the request ID
+// unless the caller set one.
+func (l *ContextLogger) fields(keysAndValues ...interface{}) map[string]string {
+	fields := toStringMap(keysAndValues...)
+	if l.ctx == nil || l.ctx.Request == nil || l.ctx.Request.RequestID == "" {
+		return fields
+	}
+	if fields == nil {
+		fields = make(map[string]string, 1)
+	}
+	if _, exists := fields["request_id"]; !exists {
+		fields["request_id"] = l.ctx.Request.RequestID
+	}
+	return fields
+}
+
 // toStringMap converts key-value pairs to map[string]string.
 func toStringMap(keysAndValues ...interface{}) map[string]string {
 	if len(keysAndValues) == 0 {